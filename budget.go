@@ -0,0 +1,87 @@
+package iter
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// BudgetBound identifies the reason an iterator of LimitBudget stopped.
+type BudgetBound int
+
+const (
+	// BudgetNotHit means the iterator didn't stop yet.
+	BudgetNotHit BudgetBound = iota
+	// BudgetSourceDone means the source stopped before any bound was hit.
+	BudgetSourceDone
+	// BudgetElements means the maximal number of elements was yielded.
+	BudgetElements
+	// BudgetDuration means the maximal duration has passed.
+	BudgetDuration
+)
+
+// BudgetStats reports the state of an iterator of LimitBudget.
+// It's safe to read the statistics concurrently with the iteration.
+type BudgetStats struct {
+	mu       sync.Mutex
+	bound    BudgetBound
+	elements int
+	elapsed  time.Duration
+}
+
+// Stats returns the bound which stopped the iterator, the number of yielded elements
+// and the time passed since the first call till the last one.
+func (s *BudgetStats) Stats() (bound BudgetBound, elements int, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bound, s.elements, s.elapsed
+}
+
+// LimitBudget returns an iterator yielding elements of the source until
+// maxElements elements are yielded or maxDuration passes since the first call,
+// whichever happens first, and the statistics of the iteration.
+//
+// The duration is checked before pulling from the source,
+// a pull blocking longer than the rest of the duration isn't interrupted.
+// A non-positive bound isn't applied.
+func LimitBudget[T any](source Iterator[T], maxElements int, maxDuration time.Duration) (Iterator[T], *BudgetStats) {
+	return LimitBudgetClock(source, maxElements, maxDuration, SystemClock)
+}
+
+// LimitBudgetClock works as LimitBudget measuring the duration with the clock.
+func LimitBudgetClock[T any](source Iterator[T], maxElements int, maxDuration time.Duration, clock Clock) (Iterator[T], *BudgetStats) {
+	stats := &BudgetStats{}
+	var start time.Time
+	started := false
+	return func() (T, error) {
+		var empty T
+		stats.mu.Lock()
+		defer stats.mu.Unlock()
+		if stats.bound != BudgetNotHit {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started, start = true, clock.Now()
+		}
+		stats.elapsed = clock.Now().Sub(start)
+		if maxElements > 0 && stats.elements >= maxElements {
+			stats.bound = BudgetElements
+			return empty, ErrStopIt
+		}
+		if maxDuration > 0 && stats.elapsed >= maxDuration {
+			stats.bound = BudgetDuration
+			return empty, ErrStopIt
+		}
+		stats.mu.Unlock()
+		value, err := source()
+		stats.mu.Lock()
+		if errors.Is(err, ErrStopIt) {
+			stats.bound = BudgetSourceDone
+		}
+		if err != nil {
+			return empty, err
+		}
+		stats.elements++
+		return value, nil
+	}, stats
+}
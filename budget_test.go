@@ -0,0 +1,73 @@
+package iter
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// tickingSource returns the range of n elements moving the clock by step before every element.
+func tickingSource(clock *fakeClock, n int, step time.Duration) Iterator[int] {
+	return Map(Range(0, n, 1), func(v int) (int, error) {
+		clock.Advance(step)
+		return v, nil
+	})
+}
+
+func TestLimitBudgetElementsFirst(t *testing.T) {
+	clock := newFakeClock()
+	it, stats := LimitBudgetClock(tickingSource(clock, 100, 10*time.Millisecond), 3, time.Hour, clock)
+	if bound, _, _ := stats.Stats(); bound != BudgetNotHit {
+		t.Fatalf("got bound %v before the iteration, want BudgetNotHit", bound)
+	}
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 3 {
+		t.Fatalf("got %v, %v, want 3 elements", got, err)
+	}
+	if bound, n, elapsed := stats.Stats(); bound != BudgetElements || n != 3 || elapsed != 30*time.Millisecond {
+		t.Fatalf("got %v, %d, %v, want BudgetElements, 3, 30ms", bound, n, elapsed)
+	}
+}
+
+func TestLimitBudgetDurationFirst(t *testing.T) {
+	clock := newFakeClock()
+	it, stats := LimitBudgetClock(tickingSource(clock, 100, 10*time.Millisecond), 50, 50*time.Millisecond, clock)
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 5 {
+		t.Fatalf("got %v, %v, want 5 elements", got, err)
+	}
+	if bound, n, elapsed := stats.Stats(); bound != BudgetDuration || n != 5 || elapsed != 50*time.Millisecond {
+		t.Fatalf("got %v, %d, %v, want BudgetDuration, 5, 50ms", bound, n, elapsed)
+	}
+}
+
+func TestLimitBudgetSourceFirst(t *testing.T) {
+	clock := newFakeClock()
+	it, stats := LimitBudgetClock(tickingSource(clock, 2, time.Millisecond), 10, time.Hour, clock)
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("got %v, %v, want 2 elements", got, err)
+	}
+	if bound, n, _ := stats.Stats(); bound != BudgetSourceDone || n != 2 {
+		t.Fatalf("got %v, %d, want BudgetSourceDone, 2", bound, n)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the end, want ErrStopIt", err)
+	}
+}
+
+func TestLimitBudgetClockStartsOnFirstPull(t *testing.T) {
+	clock := newFakeClock()
+	it, stats := LimitBudgetClock(tickingSource(clock, 100, 0), 0, 50*time.Millisecond, clock)
+	clock.Advance(time.Hour)
+	if _, err := it(); err != nil {
+		t.Fatalf("got %v on the first pull after an idle hour, want nil", err)
+	}
+	clock.Advance(50 * time.Millisecond)
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+	if bound, n, _ := stats.Stats(); bound != BudgetDuration || n != 1 {
+		t.Fatalf("got %v, %d, want BudgetDuration, 1", bound, n)
+	}
+}
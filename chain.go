@@ -0,0 +1,14 @@
+package iter
+
+// ChainLazy returns an iterator chaining iterators pulled from sources,
+// the next iterator is pulled only after the current one stops,
+// so the iterators may be constructed on demand.
+// A failure of sources or of a chained iterator is returned and stops the chain.
+func ChainLazy[T any](sources Iterator[Iterator[T]]) Iterator[T] {
+	return FlattenIterators(sources)
+}
+
+// ChainLazySafe works as ChainLazy and is safe for concurrent use.
+func ChainLazySafe[T any](sources Iterator[Iterator[T]]) Iterator[T] {
+	return safe(ChainLazy(sources))
+}
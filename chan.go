@@ -0,0 +1,277 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrSourceSilent is matched by the error returned by FanInHeartbeat
+// when one of the sources goes silent.
+var ErrSourceSilent = errors.New("source is silent")
+
+// SourceSilentError is returned by FanInHeartbeat when
+// the Source channel didn't produce an element for the Silence window.
+type SourceSilentError struct {
+	Source  int
+	Silence time.Duration
+}
+
+func (e *SourceSilentError) Error() string {
+	return fmt.Sprintf("source %d is silent for %v", e.Source, e.Silence)
+}
+
+func (e *SourceSilentError) Unwrap() error {
+	return ErrSourceSilent
+}
+
+// FanInHeartbeat returns an iterator merging elements of the channels
+// in the order they arrive.
+//
+// Every open channel must produce an element at least once in the silence window,
+// the window of every channel starts on the first call of the iterator.
+// Otherwise the iterator returns *SourceSilentError identifying the channel and stops.
+// Closed channels are no longer monitored, the iterator stops when all the channels
+// are closed. If the context is cancelled the iterator returns its error and stops.
+func FanInHeartbeat[T any](ctx context.Context, silence time.Duration, sources ...<-chan T) Iterator[T] {
+	var (
+		open     []int
+		lastSeen []time.Time
+		started  bool
+		done     bool
+	)
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started = true
+			now := time.Now()
+			for i := range sources {
+				open = append(open, i)
+				lastSeen = append(lastSeen, now)
+			}
+		}
+		for len(open) > 0 {
+			earliest := 0
+			for i := range open {
+				if lastSeen[open[i]].Before(lastSeen[open[earliest]]) {
+					earliest = i
+				}
+			}
+			timer := time.NewTimer(time.Until(lastSeen[open[earliest]].Add(silence)))
+			cases := make([]reflect.SelectCase, 0, len(open)+2)
+			cases = append(cases,
+				reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+				reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+			)
+			for _, source := range open {
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sources[source])})
+			}
+			chosen, value, ok := reflect.Select(cases)
+			timer.Stop()
+			switch chosen {
+			case 0:
+				done = true
+				return empty, ctx.Err()
+			case 1:
+				// The consumer might have been slower than the window,
+				// the source is silent only if it has nothing to send right now.
+				chosen = earliest + 2
+				value, ok = cases[chosen].Chan.TryRecv()
+				if !value.IsValid() {
+					done = true
+					return empty, &SourceSilentError{Source: open[earliest], Silence: silence}
+				}
+			}
+			i := chosen - 2
+			if !ok {
+				open = append(open[:i], open[i+1:]...)
+				continue
+			}
+			lastSeen[open[i]] = time.Now()
+			element, _ := value.Interface().(T)
+			return element, nil
+		}
+		done = true
+		return empty, ErrStopIt
+	}
+}
+
+// FromChan returns an iterator of elements received from the channel,
+// it stops when the channel is closed.
+// If the context is cancelled the iterator returns its error and stops.
+func FromChan[T any](ctx context.Context, source <-chan T) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		select {
+		case <-ctx.Done():
+			done = true
+			return empty, ctx.Err()
+		case value, ok := <-source:
+			if !ok {
+				done = true
+				return empty, ErrStopIt
+			}
+			return value, nil
+		}
+	}
+}
+
+// FromChanSimple is FromChan without a context,
+// it blocks until the channel has an element or is closed.
+func FromChanSimple[T any](source <-chan T) Iterator[T] {
+	return func() (T, error) {
+		value, ok := <-source
+		if !ok {
+			return value, ErrStopIt
+		}
+		return value, nil
+	}
+}
+
+// ToChan starts a goroutine sending elements of the iterator to the returned channel,
+// the channel is closed when the iterator stops or returns an error
+// or when the context is cancelled.
+func ToChan[T any](ctx context.Context, it Iterator[T]) <-chan T {
+	return ToChanBuffered(ctx, it, 0)
+}
+
+// ToChanBuffered is ToChan with a channel of the given capacity.
+func ToChanBuffered[T any](ctx context.Context, it Iterator[T], size int) <-chan T {
+	out, _ := toChan(ctx, it, size)
+	return out
+}
+
+// ToChanErr is ToChan reporting why the iterator stopped.
+// The error channel receives one value after the element channel is closed:
+// nil if the iterator stopped, its error if it failed
+// or the error of the context if it was cancelled.
+func ToChanErr[T any](ctx context.Context, it Iterator[T]) (<-chan T, <-chan error) {
+	return toChan(ctx, it, 0)
+}
+
+func toChan[T any](ctx context.Context, it Iterator[T], size int) (<-chan T, <-chan error) {
+	out := make(chan T, max(size, 0))
+	errs := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			close(out)
+			errs <- err
+			close(errs)
+		}()
+		for {
+			var value T
+			value, err = it()
+			if err != nil {
+				if errors.Is(err, ErrStopIt) {
+					err = nil
+				}
+				return
+			}
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			case out <- value:
+			}
+		}
+	}()
+	return out, errs
+}
+
+// Buffer returns an iterator prefetching up to size elements of the source
+// in a separate goroutine, so the source and the consumer work concurrently.
+//
+// An error of the source is returned after the elements pulled before it
+// and stops the iterator. When the context is cancelled the goroutine stops,
+// the already prefetched elements are returned and then the error of the context,
+// they are reported by DrainOnCancel and ConsumeWithCancel with the same context.
+// If the iterator is abandoned before it stops the context must be cancelled
+// to release the goroutine. Non-positive size results in an iterator
+// returning ErrInvalidArgument.
+func Buffer[T any](ctx context.Context, source Iterator[T], size int) Iterator[T] {
+	if size <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive buffer size %d", ErrInvalidArgument, size))
+	}
+	ch := make(chan result[T], size)
+	settled := make(chan struct{})
+	// leftover is the element pulled when the context got cancelled
+	// while the buffer was full, it's set before ch is closed.
+	var leftover *result[T]
+	go func() {
+		defer close(ch)
+		defer close(settled)
+		for ctx.Err() == nil {
+			value, err := source()
+			r := result[T]{value, err}
+			select {
+			case ch <- r:
+			case <-ctx.Done():
+				select {
+				case ch <- r:
+				default:
+					leftover = &r
+				}
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	release := hold(ctx, &holding{
+		held: func() int {
+			if leftover != nil {
+				return len(ch) + 1
+			}
+			return len(ch)
+		},
+		settled: settled,
+	})
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		var (
+			r        result[T]
+			received bool
+			open     bool
+		)
+		select {
+		case r, open = <-ch:
+			received = true
+		case <-ctx.Done():
+			// The source might be blocked, return what is already prefetched.
+			select {
+			case r, open = <-ch:
+				received = true
+			default:
+			}
+		}
+		switch {
+		case received && !open && leftover != nil:
+			// ch is closed, so leftover is final.
+			r = *leftover
+			leftover = nil
+		case !open:
+			r.err = ctx.Err()
+		}
+		if r.err != nil {
+			done = true
+			release()
+			return empty, r.err
+		}
+		return r.value, nil
+	}
+}
@@ -0,0 +1,204 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// produce sends n values to a new channel every interval and closes it.
+func produce(n int, interval time.Duration) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; i < n; i++ {
+			time.Sleep(interval)
+			ch <- i
+		}
+	}()
+	return ch
+}
+
+func TestFanInHeartbeatSilentSource(t *testing.T) {
+	silent := make(chan int)
+	defer close(silent)
+	busy := produce(1000, time.Millisecond)
+	defer func() {
+		for range busy {
+		}
+	}()
+	it := FanInHeartbeat(context.Background(), 50*time.Millisecond, busy, silent)
+	start := time.Now()
+	var err error
+	for err == nil {
+		_, err = it()
+	}
+	var silentErr *SourceSilentError
+	if !errors.As(err, &silentErr) || !errors.Is(err, ErrSourceSilent) || silentErr.Source != 1 {
+		t.Fatalf("got %v, want a silence error of source 1", err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond || d > time.Second {
+		t.Fatalf("silence detected after %v, want about 50ms", d)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the silence error, want ErrStopIt", err)
+	}
+}
+
+func TestFanInHeartbeatClosedSource(t *testing.T) {
+	// The first channel closes early, the second one keeps producing
+	// for much longer than the window.
+	it := FanInHeartbeat(context.Background(), 200*time.Millisecond,
+		produce(2, 0), produce(40, 10*time.Millisecond))
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 42 {
+		t.Fatalf("got %d elements, %v, want 42, nil", len(got), err)
+	}
+}
+
+func TestFanInHeartbeatCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	silent := make(chan int)
+	defer close(silent)
+	it := FanInHeartbeat(ctx, time.Hour, silent)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := it(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
+
+func TestToChanFromChanRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	var ch <-chan int = ToChan(ctx, Range(0, 100, 1))
+	got, err := ToSlice(FromChan(ctx, ch))
+	if err != nil || len(got) != 100 || got[99] != 99 {
+		t.Fatalf("got %d elements, %v, want 100, nil", len(got), err)
+	}
+	got, err = ToSlice(FromChanSimple(ToChanBuffered(ctx, Range(0, 10, 1), 4)))
+	if err != nil || len(got) != 10 {
+		t.Fatalf("got %d elements, %v, want 10, nil", len(got), err)
+	}
+}
+
+func TestFromChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan int)
+	defer close(ch)
+	it := FromChan(ctx, ch)
+	cancel()
+	if _, err := it(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
+
+func TestToChanErr(t *testing.T) {
+	failure := errors.New("failure")
+	values, errs := ToChanErr(context.Background(), ChainLazy(FromSlice([]Iterator[int]{Range(0, 3, 1), Err[int](failure)})))
+	got, _ := ToSlice(FromChanSimple(values))
+	if err := <-errs; !errors.Is(err, failure) || len(got) != 3 {
+		t.Fatalf("got %v after %d elements, want %v after 3", err, len(got), failure)
+	}
+	values, errs = ToChanErr(context.Background(), Range(0, 3, 1))
+	for range values {
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("got %v for a finished iterator, want nil", err)
+	}
+}
+
+func TestToChanErrCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	values, errs := ToChanErr(ctx, Iterate(0, func(v int) int { return v + 1 }))
+	for i := 0; i < 5; i++ {
+		if v := <-values; v != i {
+			t.Fatalf("got %d, want %d", v, i)
+		}
+	}
+	cancel()
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-values:
+		case <-timeout:
+			t.Fatal("element channel wasn't closed after cancellation")
+		}
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-timeout:
+		t.Fatal("no error after cancellation")
+	}
+	if _, open := <-errs; open {
+		t.Fatal("error channel wasn't closed")
+	}
+}
+
+// benchmarkToChan runs a steady source and a bursty consumer stalling
+// on every 8th element for as long as the source takes for 8 elements,
+// the buffer lets the source keep producing during the stalls.
+func benchmarkToChan(b *testing.B, size int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < b.N; i++ {
+		source := Range(0, 32, 1)
+		ch := ToChanBuffered(ctx, func() (int, error) {
+			time.Sleep(time.Millisecond)
+			return source()
+		}, size)
+		for v := range ch {
+			if v%8 == 7 {
+				time.Sleep(8 * time.Millisecond)
+			}
+		}
+	}
+}
+
+func BenchmarkToChan(b *testing.B) {
+	benchmarkToChan(b, 0)
+}
+
+func BenchmarkToChanBuffered(b *testing.B) {
+	benchmarkToChan(b, 8)
+}
+
+// benchmarkSlowSource runs a source and a consumer spending the same time per element,
+// wrap lets them overlap.
+func benchmarkSlowSource(b *testing.B, wrap func(context.Context, Iterator[int]) Iterator[int]) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for i := 0; i < b.N; i++ {
+		source := Range(0, 20, 1)
+		it := wrap(ctx, func() (int, error) {
+			time.Sleep(100 * time.Microsecond)
+			return source()
+		})
+		for {
+			if _, err := it(); err != nil {
+				break
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+}
+
+func BenchmarkSlowSource(b *testing.B) {
+	benchmarkSlowSource(b, func(_ context.Context, source Iterator[int]) Iterator[int] {
+		return source
+	})
+}
+
+func BenchmarkSlowSourceBuffer(b *testing.B) {
+	benchmarkSlowSource(b, func(ctx context.Context, source Iterator[int]) Iterator[int] {
+		return Buffer(ctx, source, 8)
+	})
+}
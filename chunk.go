@@ -0,0 +1,230 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ChunkKeyed returns an iterator of chunks of up to maxSize elements
+// where elements with the same key are never split between chunks.
+//
+// Consecutive elements with an equal key form a group, a group is added
+// to the current chunk only if it fits, otherwise it starts a new chunk.
+// A single group larger than maxSize is returned as one chunk overflowing maxSize.
+// The last partial chunk is returned when the source is exhausted.
+// An error from the source drops the chunk in progress and is returned.
+// Non-positive maxSize results in an iterator returning ErrInvalidArgument.
+func ChunkKeyed[T any, K comparable](source Iterator[T], maxSize int, key func(T) K) Iterator[[]T] {
+	if maxSize <= 0 {
+		return Err[[]T](fmt.Errorf("%w: non-positive chunk size %d", ErrInvalidArgument, maxSize))
+	}
+	var (
+		chunk    []T
+		group    []T
+		groupKey K
+		done     bool
+	)
+	return func() ([]T, error) {
+		for !done {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				chunk, group = nil, nil
+				return nil, err
+			}
+			k := key(value)
+			if len(group) == 0 || k == groupKey {
+				group = append(group, value)
+				groupKey = k
+				continue
+			}
+			if len(chunk) > 0 && len(chunk)+len(group) > maxSize {
+				ready := chunk
+				chunk, group, groupKey = group, []T{value}, k
+				return ready, nil
+			}
+			chunk = append(chunk, group...)
+			group, groupKey = []T{value}, k
+			if len(chunk) >= maxSize {
+				ready := chunk
+				chunk = nil
+				return ready, nil
+			}
+		}
+		if len(chunk) > 0 && len(chunk)+len(group) > maxSize {
+			ready := chunk
+			chunk = nil
+			return ready, nil
+		}
+		ready := append(chunk, group...)
+		chunk, group = nil, nil
+		if len(ready) == 0 {
+			return nil, ErrStopIt
+		}
+		return ready, nil
+	}
+}
+
+// SessionWindows returns an iterator grouping time-ordered elements into sessions,
+// a session ends when the time between consecutive elements exceeds gap.
+//
+// The last session is returned when the source is exhausted.
+// An error from the source drops the session in progress and is returned.
+// A timestamp less than the previous one results in ErrUnsorted and stops the iterator.
+func SessionWindows[T any](source Iterator[Pair[time.Time, T]], gap time.Duration) Iterator[[]Pair[time.Time, T]] {
+	var (
+		session []Pair[time.Time, T]
+		done    bool
+	)
+	return func() ([]Pair[time.Time, T], error) {
+		for !done {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				session = nil
+				return nil, err
+			}
+			if len(session) == 0 {
+				session = append(session, value)
+				continue
+			}
+			last := session[len(session)-1].Left
+			if value.Left.Before(last) {
+				done, session = true, nil
+				return nil, fmt.Errorf("%w: %v after %v", ErrUnsorted, value.Left, last)
+			}
+			if value.Left.Sub(last) > gap {
+				ready := session
+				session = []Pair[time.Time, T]{value}
+				return ready, nil
+			}
+			session = append(session, value)
+		}
+		if len(session) == 0 {
+			return nil, ErrStopIt
+		}
+		ready := session
+		session = nil
+		return ready, nil
+	}
+}
+
+// Window returns an iterator of sliding windows of size elements
+// advancing by step elements, every window is a new slice.
+//
+// A window shorter than size at the end of the source isn't yielded.
+// If step is greater than size the elements between windows are skipped.
+// Non-positive size or step results in an iterator returning ErrInvalidArgument.
+func Window[T any](source Iterator[T], size, step int) Iterator[[]T] {
+	if size <= 0 || step <= 0 {
+		return Err[[]T](fmt.Errorf("%w: non-positive window size %d or step %d", ErrInvalidArgument, size, step))
+	}
+	ring := make([]T, size)
+	start, count, skip := 0, 0, 0
+	done := false
+	return func() ([]T, error) {
+		for !done && skip > 0 {
+			_, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			skip--
+		}
+		for !done && count < size {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			ring[(start+count)%size] = value
+			count++
+		}
+		if done {
+			return nil, ErrStopIt
+		}
+		window := make([]T, size)
+		n := copy(window, ring[start:])
+		copy(window[n:], ring[:start])
+		if step < size {
+			start = (start + step) % size
+			count -= step
+		} else {
+			start, count, skip = 0, 0, step-size
+		}
+		return window, nil
+	}
+}
+
+// AdaptiveChunk returns an iterator of chunks with the size adjusted to make
+// processing of a chunk take about the target duration.
+//
+// Before filling every chunk but the first one observe is called to get
+// the processing duration of the previous chunk, and the size is multiplied
+// by the ratio of target to the observed duration limited to [1/2, 2],
+// rounded up when it grows and then clamped to [minSize, maxSize].
+// The first chunk has minSize elements.
+// The last partial chunk is returned when the source is exhausted.
+// An error from the source drops the chunk in progress and is returned.
+// Non-positive minSize or target, maxSize less than minSize or nil observe
+// result in an iterator returning ErrInvalidArgument.
+func AdaptiveChunk[T any](source Iterator[T], minSize, maxSize int, target time.Duration, observe func() time.Duration) Iterator[[]T] {
+	if minSize <= 0 || maxSize < minSize || target <= 0 {
+		return Err[[]T](fmt.Errorf("%w: chunk sizes [%d, %d] and target %v",
+			ErrInvalidArgument, minSize, maxSize, target))
+	}
+	if observe == nil {
+		return Err[[]T](fmt.Errorf("%w: nil observe", ErrInvalidArgument))
+	}
+	size := minSize
+	emitted, done := false, false
+	return func() ([]T, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		if emitted {
+			emitted = false
+			factor := 2.0
+			if observed := observe(); observed > 0 {
+				factor = min(max(float64(target)/float64(observed), 0.5), 2)
+			}
+			next := float64(size) * factor
+			if factor > 1 {
+				// Rounding down would never let a small size grow.
+				next = math.Ceil(next)
+			}
+			size = min(max(int(next), minSize), maxSize)
+		}
+		chunk := make([]T, 0, size)
+		for len(chunk) < size {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			chunk = append(chunk, value)
+		}
+		if len(chunk) == 0 {
+			return nil, ErrStopIt
+		}
+		emitted = true
+		return chunk, nil
+	}
+}
@@ -0,0 +1,249 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// keyedRuns returns elements with the keys of the letters, numbered in order.
+func keyedRuns(keys string) []keyed {
+	values := make([]keyed, len(keys))
+	for i, k := range keys {
+		values[i] = keyed{key: string(k), seq: i}
+	}
+	return values
+}
+
+func chunkKeys(t *testing.T, input []keyed, maxSize int) []string {
+	t.Helper()
+	chunks, err := ToSlice(ChunkKeyed(FromSlice(input), maxSize, func(k keyed) string { return k.key }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var flat []keyed
+	keys := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		var b strings.Builder
+		for _, v := range chunk {
+			b.WriteString(v.key)
+		}
+		keys[i] = b.String()
+		flat = append(flat, chunk...)
+	}
+	if !slices.Equal(flat, input) {
+		t.Fatalf("chunks %v don't keep the elements and their order", chunks)
+	}
+	return keys
+}
+
+func TestChunkKeyedBoundary(t *testing.T) {
+	got := chunkKeys(t, keyedRuns("aabbccdddd"), 4)
+	if want := []string{"aabb", "cc", "dddd"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestChunkKeyedLargeGroup(t *testing.T) {
+	got := chunkKeys(t, keyedRuns("abbbbbc"), 2)
+	if want := []string{"a", "bbbbb", "c"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestChunkKeyedOrder(t *testing.T) {
+	got := chunkKeys(t, keyedRuns("aaabccddde"), 5)
+	if want := []string{"aaab", "ccddd", "e"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestChunkKeyedErrors(t *testing.T) {
+	failure := errors.New("failure")
+	source := Map(FromSlice(keyedRuns("aab")), func(k keyed) (keyed, error) {
+		if k.key == "b" {
+			return k, failure
+		}
+		return k, nil
+	})
+	it := ChunkKeyed(source, 10, func(k keyed) string { return k.key })
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	invalid := ChunkKeyed(Range(0, 3, 1), 0, func(v int) int { return v })
+	if _, err := invalid(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+// sessions returns the sizes of SessionWindows with the gap of 10 seconds
+// over elements at the seconds, and the error which stopped it.
+func sessions(seconds ...int) ([]int, error) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	values := make([]Pair[time.Time, int], len(seconds))
+	for i, s := range seconds {
+		values[i] = Pair[time.Time, int]{Left: start.Add(time.Duration(s) * time.Second), Right: i}
+	}
+	it := SessionWindows(FromSlice(values), 10*time.Second)
+	var sizes []int
+	for {
+		session, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return sizes, nil
+		}
+		if err != nil {
+			return sizes, err
+		}
+		for i, p := range session {
+			if i > 0 && p.Right != session[i-1].Right+1 {
+				return sizes, fmt.Errorf("session %v isn't in the source order", session)
+			}
+		}
+		sizes = append(sizes, len(session))
+	}
+}
+
+func TestSessionWindowsExactGap(t *testing.T) {
+	// The gap equal to the threshold doesn't end the session.
+	got, err := sessions(0, 10, 20, 31, 41)
+	if err != nil || !slices.Equal(got, []int{3, 2}) {
+		t.Fatalf("got %v, %v, want [3 2], nil", got, err)
+	}
+}
+
+func TestSessionWindowsSingleElement(t *testing.T) {
+	got, err := sessions(0, 100, 105, 200)
+	if err != nil || !slices.Equal(got, []int{1, 2, 1}) {
+		t.Fatalf("got %v, %v, want [1 2 1], nil", got, err)
+	}
+	if got, err = sessions(5); err != nil || !slices.Equal(got, []int{1}) {
+		t.Fatalf("got %v, %v, want [1], nil", got, err)
+	}
+	if got, err = sessions(); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v for an empty source, want no sessions", got, err)
+	}
+}
+
+func TestSessionWindowsUnsorted(t *testing.T) {
+	got, err := sessions(0, 5, 30, 29, 40)
+	if !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("got %v, want ErrUnsorted", err)
+	}
+	// The session before the regression is complete, the one in progress is dropped.
+	if !slices.Equal(got, []int{2}) {
+		t.Fatalf("got sessions %v before the error, want [2]", got)
+	}
+	// Equal timestamps aren't a regression.
+	if got, err = sessions(0, 0, 0); err != nil || !slices.Equal(got, []int{3}) {
+		t.Fatalf("got %v, %v, want [3], nil", got, err)
+	}
+}
+
+func TestSessionWindowsError(t *testing.T) {
+	failure := errors.New("failure")
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	it := SessionWindows(ChainLazy(FromSlice([]Iterator[Pair[time.Time, int]]{
+		FromSlice([]Pair[time.Time, int]{{Left: start}, {Left: start.Add(time.Second)}}),
+		Err[Pair[time.Time, int]](failure),
+	})), time.Minute)
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+// adaptiveSizes returns the sizes of n chunks of AdaptiveChunk processed
+// with the synthetic latency of 5ms per chunk and 1ms per element.
+func adaptiveSizes(t *testing.T, n, minSize, maxSize int, target time.Duration) []int {
+	t.Helper()
+	var last int
+	it := AdaptiveChunk(Iterate(0, func(v int) int { return v + 1 }), minSize, maxSize, target, func() time.Duration {
+		return 5*time.Millisecond + time.Duration(last)*time.Millisecond
+	})
+	sizes := make([]int, n)
+	for i := range sizes {
+		chunk, err := it()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[i], last = len(chunk), len(chunk)
+	}
+	return sizes
+}
+
+func TestAdaptiveChunkConverges(t *testing.T) {
+	sizes := adaptiveSizes(t, 30, 1, 1000, 100*time.Millisecond)
+	if sizes[0] != 1 {
+		t.Fatalf("first chunk of %d elements, want minSize", sizes[0])
+	}
+	// 95 elements take the target 100ms.
+	for i, size := range sizes[20:] {
+		if size < 90 || size > 100 {
+			t.Fatalf("chunk %d of %d elements, want about 95: %v", i+20, size, sizes)
+		}
+	}
+	// The size at most doubles per chunk.
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i] > 2*sizes[i-1] {
+			t.Fatalf("chunk %d grew from %d to %d", i, sizes[i-1], sizes[i])
+		}
+	}
+}
+
+func TestAdaptiveChunkBounds(t *testing.T) {
+	for _, size := range adaptiveSizes(t, 20, 1, 50, 100*time.Millisecond)[10:] {
+		if size != 50 {
+			t.Fatalf("got chunk of %d elements, want it clamped to maxSize 50", size)
+		}
+	}
+	for _, size := range adaptiveSizes(t, 20, 200, 400, 100*time.Millisecond) {
+		if size != 200 {
+			t.Fatalf("got chunk of %d elements, want it clamped to minSize 200", size)
+		}
+	}
+}
+
+func TestAdaptiveChunkPartial(t *testing.T) {
+	it := AdaptiveChunk(Range(0, 10, 1), 4, 4, time.Second, func() time.Duration { return time.Second })
+	chunks, err := ToSlice(it)
+	if err != nil || len(chunks) != 3 || len(chunks[2]) != 2 || chunks[2][1] != 9 {
+		t.Fatalf("got %v, %v, want chunks of 4, 4 and 2 elements", chunks, err)
+	}
+}
+
+func TestAdaptiveChunkSlowGrowth(t *testing.T) {
+	// The target is 10% above the observed duration, truncation would keep size 1.
+	it := AdaptiveChunk(Iterate(0, func(v int) int { return v + 1 }), 1, 100, 110*time.Millisecond,
+		func() time.Duration { return 100 * time.Millisecond })
+	sizes := make([]int, 10)
+	for i := range sizes {
+		chunk, err := it()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[i] = len(chunk)
+	}
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i] <= sizes[i-1] {
+			t.Fatalf("chunk %d didn't grow: %v", i, sizes)
+		}
+	}
+}
+
+func TestAdaptiveChunkErrors(t *testing.T) {
+	failure := errors.New("failure")
+	if _, err := AdaptiveChunk(Err[int](failure), 1, 2, time.Second, func() time.Duration { return 0 })(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	for _, tc := range [][2]int{{0, 1}, {3, 2}} {
+		it := AdaptiveChunk(Range(0, 3, 1), tc[0], tc[1], time.Second, func() time.Duration { return 0 })
+		if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("sizes %v: got %v, want ErrInvalidArgument", tc, err)
+		}
+	}
+	if _, err := AdaptiveChunk(Range(0, 3, 1), 1, 2, time.Second, nil)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v for nil observe, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,62 @@
+package iter
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Codec encodes elements to a byte stream and decodes them back,
+// it's used by iterators spilling elements to disk.
+type Codec[T any] interface {
+	// Encoder returns a function writing encoded elements to w.
+	Encoder(w io.Writer) func(T) error
+	// Decoder returns an iterator of elements decoded from r,
+	// the iterator stops at the end of r.
+	Decoder(r io.Reader) Iterator[T]
+}
+
+// GobCodec is a Codec using encoding/gob.
+type GobCodec[T any] struct{}
+
+func (GobCodec[T]) Encoder(w io.Writer) func(T) error {
+	encoder := gob.NewEncoder(w)
+	return func(value T) error {
+		return encoder.Encode(value)
+	}
+}
+
+func (GobCodec[T]) Decoder(r io.Reader) Iterator[T] {
+	decoder := gob.NewDecoder(r)
+	return func() (T, error) {
+		var value T
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			err = ErrStopIt
+		}
+		return value, err
+	}
+}
+
+// JSONCodec is a Codec using encoding/json.
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encoder(w io.Writer) func(T) error {
+	encoder := json.NewEncoder(w)
+	return func(value T) error {
+		return encoder.Encode(value)
+	}
+}
+
+func (JSONCodec[T]) Decoder(r io.Reader) Iterator[T] {
+	decoder := json.NewDecoder(r)
+	return func() (T, error) {
+		var value T
+		err := decoder.Decode(&value)
+		if errors.Is(err, io.EOF) {
+			err = ErrStopIt
+		}
+		return value, err
+	}
+}
@@ -0,0 +1,134 @@
+package iter
+
+// pick returns a new slice of values at the indices.
+func pick[T any](values []T, indices []int) []T {
+	result := make([]T, len(indices))
+	for i, index := range indices {
+		result[i] = values[index]
+	}
+	return result
+}
+
+// Permutations returns an iterator over all permutations of values
+// in lexicographic order of indices, every permutation is a new slice.
+// Empty values yield a single empty permutation.
+func Permutations[T any](values []T) Iterator[[]T] {
+	indices := make([]int, len(values))
+	for i := range indices {
+		indices[i] = i
+	}
+	done := false
+	return func() ([]T, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		result := pick(values, indices)
+		// Next permutation: find the last ascent, swap it with the smallest
+		// greater element after it and reverse the tail.
+		i := len(indices) - 2
+		for i >= 0 && indices[i] > indices[i+1] {
+			i--
+		}
+		if i < 0 {
+			done = true
+			return result, nil
+		}
+		j := len(indices) - 1
+		for indices[j] < indices[i] {
+			j--
+		}
+		indices[i], indices[j] = indices[j], indices[i]
+		for l, r := i+1, len(indices)-1; l < r; l, r = l+1, r-1 {
+			indices[l], indices[r] = indices[r], indices[l]
+		}
+		return result, nil
+	}
+}
+
+// Combinations returns an iterator over all k-element combinations of values
+// in lexicographic order of indices, every combination is a new slice.
+// k == 0 yields a single empty combination, negative k or k > len(values)
+// make the iterator empty.
+func Combinations[T any](values []T, k int) Iterator[[]T] {
+	done := k < 0 || k > len(values)
+	var indices []int
+	if !done {
+		indices = make([]int, k)
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+	return func() ([]T, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		result := pick(values, indices)
+		// Next combination: increase the last index that is below
+		// its maximum and reset the following ones right after it.
+		i := k - 1
+		for i >= 0 && indices[i] == len(values)-k+i {
+			i--
+		}
+		if i < 0 {
+			done = true
+			return result, nil
+		}
+		indices[i]++
+		for j := i + 1; j < k; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+		return result, nil
+	}
+}
+
+// Product returns an iterator over the cartesian product of slices,
+// every element is a new slice with one value from each of them.
+// The last slice changes fastest, like digits of an odometer.
+//
+// The product is empty if any of slices is empty,
+// no slices yield a single empty element.
+func Product[T any](slices ...[]T) Iterator[[]T] {
+	done := false
+	for _, values := range slices {
+		if len(values) == 0 {
+			done = true
+		}
+	}
+	indices := make([]int, len(slices))
+	return func() ([]T, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		result := make([]T, len(slices))
+		for i, index := range indices {
+			result[i] = slices[i][index]
+		}
+		i := len(indices) - 1
+		for ; i >= 0; i-- {
+			indices[i]++
+			if indices[i] < len(slices[i]) {
+				break
+			}
+			indices[i] = 0
+		}
+		done = i < 0
+		return result, nil
+	}
+}
+
+// Product2 returns an iterator over the cartesian product of two slices
+// of possibly different types, values of b change fastest.
+func Product2[T, K any](a []T, b []K) Iterator[Pair[T, K]] {
+	i, j := 0, 0
+	return func() (Pair[T, K], error) {
+		if i >= len(a) || len(b) == 0 {
+			return Pair[T, K]{}, ErrStopIt
+		}
+		result := Pair[T, K]{Left: a[i], Right: b[j]}
+		j++
+		if j == len(b) {
+			i, j = i+1, 0
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,116 @@
+package iter
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPermutations(t *testing.T) {
+	for n, want := range []int{1, 1, 2, 6, 24, 120, 720} {
+		values := make([]int, n)
+		for i := range values {
+			values[i] = i
+		}
+		got, err := ToSlice(Permutations(values))
+		if err != nil || len(got) != want {
+			t.Fatalf("n %d: got %d permutations, %v, want %d", n, len(got), err, want)
+		}
+		seen := make(map[string]bool)
+		for i, p := range got {
+			sorted := slices.Clone(p)
+			slices.Sort(sorted)
+			if !slices.Equal(sorted, values) {
+				t.Fatalf("n %d: %v isn't a permutation", n, p)
+			}
+			key := fmt.Sprint(p)
+			if seen[key] {
+				t.Fatalf("n %d: %v repeated", n, p)
+			}
+			seen[key] = true
+			if i > 0 && slices.Compare(got[i-1], p) >= 0 {
+				t.Fatalf("n %d: %v after %v, want lexicographic order", n, p, got[i-1])
+			}
+		}
+	}
+}
+
+func TestPermutationsOwned(t *testing.T) {
+	it := Permutations([]string{"a", "b"})
+	first, _ := it()
+	first[0] = "changed"
+	if second, _ := it(); !slices.Equal(second, []string{"b", "a"}) {
+		t.Fatalf("got %v after changing the first permutation, want [b a]", second)
+	}
+}
+
+func binomial(n, k int) int {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1
+	for i := 1; i <= k; i++ {
+		result = result * (n - k + i) / i
+	}
+	return result
+}
+
+func TestCombinations(t *testing.T) {
+	values := []int{0, 1, 2, 3, 4, 5}
+	for k := -1; k <= len(values)+1; k++ {
+		got, err := ToSlice(Combinations(values, k))
+		if err != nil || len(got) != binomial(len(values), k) {
+			t.Fatalf("k %d: got %d combinations, %v, want %d", k, len(got), err, binomial(len(values), k))
+		}
+		for i, c := range got {
+			if len(c) != k || !slices.IsSorted(c) || len(slices.Compact(slices.Clone(c))) != k {
+				t.Fatalf("k %d: %v isn't a combination", k, c)
+			}
+			if i > 0 && slices.Compare(got[i-1], c) >= 0 {
+				t.Fatalf("k %d: %v after %v, want lexicographic order", k, c, got[i-1])
+			}
+		}
+	}
+}
+
+func TestCombinationsEdgeCases(t *testing.T) {
+	got, err := ToSlice(Combinations([]string{}, 0))
+	if err != nil || len(got) != 1 || len(got[0]) != 0 {
+		t.Fatalf("got %v, %v for no values and k 0, want one empty combination", got, err)
+	}
+	got, err = ToSlice(Combinations([]string{"a", "b", "c"}, 3))
+	if err != nil || len(got) != 1 || !slices.Equal(got[0], []string{"a", "b", "c"}) {
+		t.Fatalf("got %v, %v for k == n, want [[a b c]]", got, err)
+	}
+	got, err = ToSlice(Combinations([]string{"a", "b", "c"}, 1))
+	if err != nil || len(got) != 3 || got[2][0] != "c" {
+		t.Fatalf("got %v, %v for k 1, want [[a] [b] [c]]", got, err)
+	}
+}
+
+func TestProduct(t *testing.T) {
+	got, err := ToSlice(Product([]int{1, 2}, []int{3}, []int{4, 5, 6}))
+	if err != nil || len(got) != 2*1*3 {
+		t.Fatalf("got %d elements, %v, want 6", len(got), err)
+	}
+	// The last slice changes fastest.
+	if want := [][]int{{1, 3, 4}, {1, 3, 5}, {1, 3, 6}, {2, 3, 4}}; !slices.EqualFunc(got[:4], want, slices.Equal[[]int]) {
+		t.Fatalf("got %v, want it to start with %v", got, want)
+	}
+	if got, err := ToSlice(Product([]int{1, 2}, nil)); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v with an empty slice, want no elements", got, err)
+	}
+	if got, err := ToSlice(Product[int]()); err != nil || len(got) != 1 || len(got[0]) != 0 {
+		t.Fatalf("got %v, %v without slices, want one empty element", got, err)
+	}
+}
+
+func TestProduct2(t *testing.T) {
+	got, err := ToSlice(Product2([]int{1, 2, 3}, []string{"a", "b"}))
+	if err != nil || len(got) != 6 || got[1] != (Pair[int, string]{1, "b"}) || got[5] != (Pair[int, string]{3, "b"}) {
+		t.Fatalf("got %v, %v, want 6 pairs with b changing fastest", got, err)
+	}
+	if got, err := ToSlice(Product2([]int{1}, []string{})); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v with an empty slice, want no pairs", got, err)
+	}
+}
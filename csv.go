@@ -0,0 +1,112 @@
+package iter
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+type csvConfig struct {
+	comma       rune
+	lazyQuotes  bool
+	reuseRecord bool
+	header      *[]string
+}
+
+// CSVOption configures FromCSV.
+type CSVOption func(*csvConfig)
+
+// WithComma sets the field delimiter, it is ',' by default.
+func WithComma(comma rune) CSVOption {
+	return func(c *csvConfig) {
+		c.comma = comma
+	}
+}
+
+// WithLazyQuotes allows quotes in unquoted fields and non-doubled quotes in quoted fields.
+func WithLazyQuotes() CSVOption {
+	return func(c *csvConfig) {
+		c.lazyQuotes = true
+	}
+}
+
+// WithReuseRecord makes the iterator reuse the slice of the previous record.
+// The yielded slice is valid only until the next call of the iterator.
+func WithReuseRecord() CSVOption {
+	return func(c *csvConfig) {
+		c.reuseRecord = true
+	}
+}
+
+// WithHeader makes the iterator treat the first record as a header,
+// it is stored to header before the first record is yielded.
+func WithHeader(header *[]string) CSVOption {
+	return func(c *csvConfig) {
+		c.header = header
+	}
+}
+
+// FromCSV returns an iterator of records read from r with encoding/csv.
+//
+// io.EOF is converted to ErrStopIt, any other error including
+// *csv.ParseError with the position of the problem is returned as is.
+func FromCSV(r io.Reader, opts ...CSVOption) Iterator[[]string] {
+	config := csvConfig{comma: ','}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	reader := csv.NewReader(r)
+	reader.Comma = config.comma
+	reader.LazyQuotes = config.lazyQuotes
+	reader.ReuseRecord = config.reuseRecord
+	header := config.header != nil
+	return func() ([]string, error) {
+		if header {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return nil, ErrStopIt
+			}
+			if err != nil {
+				return nil, err
+			}
+			header = false
+			*config.header = append([]string(nil), record...)
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrStopIt
+		}
+		return record, err
+	}
+}
+
+// ToCSV consumes the iterator writing every record to w with encoding/csv.
+// Written records are flushed before returning.
+// It returns the first error of the iterator or w.
+func ToCSV(w io.Writer, it Iterator[[]string]) error {
+	writer := csv.NewWriter(w)
+	for {
+		record, err := it()
+		if err != nil {
+			writer.Flush()
+			if errors.Is(err, ErrStopIt) {
+				return writer.Error()
+			}
+			return err
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+}
+
+// ToCSVFunc is ToCSV for elements converted to records with encode.
+func ToCSVFunc[T any](w io.Writer, it Iterator[T], encode func(T) []string) error {
+	return ToCSV(w, func() ([]string, error) {
+		value, err := it()
+		if err != nil {
+			return nil, err
+		}
+		return encode(value), nil
+	})
+}
@@ -0,0 +1,65 @@
+package iter
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	records := [][]string{
+		{"id", "name", "note"},
+		{"1", "ann", "plain"},
+		{"2", "bob, jr", `quoted "note"`},
+		{"3", "", "multi\nline"},
+	}
+	path := filepath.Join(t.TempDir(), "records.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ToCSV(f, FromSlice(records)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var header []string
+	got, err := ToSlice(FromCSV(f, WithHeader(&header)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(header, records[0]) || !slices.EqualFunc(got, records[1:], slices.Equal[[]string]) {
+		t.Fatalf("got header %q and %q, want %q and %q", header, got, records[0], records[1:])
+	}
+}
+
+func TestToCSVFunc(t *testing.T) {
+	var b strings.Builder
+	err := ToCSVFunc(&b, FromSlice([]Pair[string, int]{{"a", 1}, {"b", 2}}), func(p Pair[string, int]) []string {
+		return []string{p.Left, strings.Repeat("*", p.Right)}
+	})
+	if err != nil || b.String() != "a,*\nb,**\n" {
+		t.Fatalf("got %q, %v", b.String(), err)
+	}
+}
+
+func TestFromCSVParseError(t *testing.T) {
+	it := FromCSV(strings.NewReader("a;b\nc;\"d\n"), WithComma(';'))
+	if record, err := it(); err != nil || !slices.Equal(record, []string{"a", "b"}) {
+		t.Fatalf("got %q, %v, want [a b], nil", record, err)
+	}
+	var parseErr *csv.ParseError
+	if _, err := it(); !errors.As(err, &parseErr) || parseErr.Line != 2 {
+		t.Fatalf("got %v, want a parse error on line 2", err)
+	}
+}
@@ -0,0 +1,57 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrSharedIterator is returned by iterators of SingleUse
+// when they are used by more than one owner.
+var ErrSharedIterator = errors.New("iterator is shared")
+
+// SingleUse returns an iterator passing through elements of the source
+// which detects accidental sharing of the source, and a hand off function.
+//
+// Concurrent calls of the iterator return ErrSharedIterator instead of pulling.
+// The hand off function returns a new iterator for the next owner of the source,
+// after that calls of the previous iterator return ErrSharedIterator.
+// The hand off waits for a call in progress to finish.
+// Iterator is a function type and can't have a HandOff method,
+// so the hand off function is returned next to the iterator instead.
+//
+// The overhead is one atomic compare-and-swap taking the in-flight flag
+// and one atomic store clearing it per call, a plain store of the flag
+// would race with the compare-and-swap of a concurrent call.
+func SingleUse[T any](source Iterator[T]) (Iterator[T], func() Iterator[T]) {
+	// The state keeps the generation of the current owner shifted by one bit
+	// and the in-flight flag in the lowest bit.
+	var state atomic.Uint64
+	var owner func(generation uint64) Iterator[T]
+	owner = func(generation uint64) Iterator[T] {
+		return func() (T, error) {
+			idle := generation << 1
+			if !state.CompareAndSwap(idle, idle|1) {
+				var empty T
+				if current := state.Load(); current>>1 != generation {
+					return empty, fmt.Errorf("%w: pull by the owner %d after hand off to the owner %d",
+						ErrSharedIterator, generation, current>>1)
+				}
+				return empty, fmt.Errorf("%w: concurrent pull by the owner %d", ErrSharedIterator, generation)
+			}
+			defer state.Store(idle)
+			return source()
+		}
+	}
+	return owner(0), func() Iterator[T] {
+		for {
+			current := state.Load()
+			next := (current>>1 + 1) << 1
+			if state.CompareAndSwap(current&^1, next) {
+				return owner(next >> 1)
+			}
+			runtime.Gosched()
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package iter
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSingleUseSingleOwner(t *testing.T) {
+	it, _ := SingleUse(Range(0, 100, 1))
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 100 {
+		t.Fatalf("got %d elements, %v, want 100, nil", len(got), err)
+	}
+}
+
+func TestSingleUseConcurrentPull(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	it, _ := SingleUse(func() (int, error) {
+		close(entered)
+		<-release
+		return 1, nil
+	})
+	finished := make(chan error, 1)
+	go func() {
+		_, err := it()
+		finished <- err
+	}()
+	<-entered
+	if _, err := it(); !errors.Is(err, ErrSharedIterator) {
+		t.Fatalf("got %v for a concurrent pull, want ErrSharedIterator", err)
+	}
+	close(release)
+	if err := <-finished; err != nil {
+		t.Fatalf("got %v for the first pull, want nil", err)
+	}
+}
+
+func TestSingleUseConcurrentMisuse(t *testing.T) {
+	// The source isn't safe for concurrent use, the race detector
+	// reports if SingleUse lets two pulls reach it.
+	values := make([]int, 10000)
+	for i := range values {
+		values[i] = i
+	}
+	it, _ := SingleUse(FromSlice(values))
+	var (
+		mu     sync.Mutex
+		seen   = make(map[int]bool)
+		shared int
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := it()
+				if errors.Is(err, ErrStopIt) {
+					return
+				}
+				mu.Lock()
+				switch {
+				case errors.Is(err, ErrSharedIterator):
+					shared++
+				case err != nil:
+					t.Error(err)
+				case seen[v]:
+					t.Errorf("value %d yielded twice", v)
+				default:
+					seen[v] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != len(values) {
+		t.Fatalf("got %d distinct values, want %d", len(seen), len(values))
+	}
+	t.Logf("%d shared pulls detected", shared)
+}
+
+func TestSingleUseHandOff(t *testing.T) {
+	first, handOff := SingleUse(Range(0, 10, 1))
+	if v, err := first(); err != nil || v != 0 {
+		t.Fatalf("got %v, %v, want 0, nil", v, err)
+	}
+	second := handOff()
+	if _, err := first(); !errors.Is(err, ErrSharedIterator) {
+		t.Fatalf("got %v for the previous owner, want ErrSharedIterator", err)
+	}
+	if v, err := second(); err != nil || v != 1 {
+		t.Fatalf("got %v, %v for the new owner, want 1, nil", v, err)
+	}
+	third := handOff()
+	if _, err := second(); !errors.Is(err, ErrSharedIterator) {
+		t.Fatalf("got %v for the previous owner, want ErrSharedIterator", err)
+	}
+	if v, err := third(); err != nil || v != 2 {
+		t.Fatalf("got %v, %v for the new owner, want 2, nil", v, err)
+	}
+}
+
+func TestSingleUseHandOffWaitsForPull(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	it, handOff := SingleUse(func() (int, error) {
+		close(entered)
+		<-release
+		return 1, nil
+	})
+	go func() { _, _ = it() }()
+	<-entered
+	handedOff := make(chan struct{})
+	go func() {
+		handOff()
+		close(handedOff)
+	}()
+	select {
+	case <-handedOff:
+		t.Fatal("hand off didn't wait for the pull in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-handedOff:
+	case <-time.After(time.Second):
+		t.Fatal("hand off didn't finish after the pull")
+	}
+}
@@ -0,0 +1,189 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CoalesceErrors returns an iterator yielding values of the source
+// and reporting its errors instead of returning them.
+//
+// The first error of a run of consecutive identical errors is reported
+// immediately with count 1, then the repeated errors are suppressed and reported
+// once per window with the number of occurrences since the previous report.
+// Errors are identical if they have the same message or the new one matches
+// the previous one with errors.Is. A value, a different error or the end
+// of the source ends the run and reports its suppressed errors.
+// ErrStopIt is never reported.
+// After an error the source is called again, so it's meant for sources
+// with element-local errors like Map, a source which stops after an error
+// ends the iterator.
+func CoalesceErrors[T any](source Iterator[T], window time.Duration, report func(err error, count int)) Iterator[T] {
+	return CoalesceErrorsClock(source, window, report, SystemClock)
+}
+
+// CoalesceErrorsClock works as CoalesceErrors measuring windows with the clock.
+func CoalesceErrorsClock[T any](source Iterator[T], window time.Duration, report func(err error, count int), clock Clock) Iterator[T] {
+	var (
+		current    error
+		started    time.Time
+		suppressed int
+	)
+	flush := func() {
+		if suppressed > 0 {
+			report(current, suppressed)
+		}
+		current, suppressed = nil, 0
+	}
+	return func() (T, error) {
+		for {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				flush()
+				return value, err
+			}
+			if err == nil {
+				flush()
+				return value, nil
+			}
+			now := clock.Now()
+			if current != nil && (err.Error() == current.Error() || errors.Is(err, current)) {
+				if now.Sub(started) < window {
+					suppressed++
+					continue
+				}
+				report(current, suppressed+1)
+				started, suppressed = now, 0
+				continue
+			}
+			flush()
+			report(err, 1)
+			current, started = err, now
+		}
+	}
+}
+
+// Catch returns an iterator yielding values of the source and passing
+// its errors to handle. If handle returns nil the element is skipped,
+// ErrStopIt stops the iterator and any other error is returned.
+// ErrStopIt of the source stops the iterator without calling handle.
+func Catch[T any](source Iterator[T], handle func(error) error) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		for !done {
+			value, err := source()
+			if err == nil {
+				return value, nil
+			}
+			if errors.Is(err, ErrStopIt) {
+				break
+			}
+			err = handle(err)
+			if errors.Is(err, ErrStopIt) {
+				break
+			}
+			if err != nil {
+				return empty, err
+			}
+		}
+		done = true
+		return empty, ErrStopIt
+	}
+}
+
+// SkipErrors returns an iterator yielding values of the source
+// and skipping its errors, it stops only when the source stops.
+// A permanently failing source makes it spin forever,
+// use SkipErrorsN to limit the number of errors in a row.
+func SkipErrors[T any](source Iterator[T]) Iterator[T] {
+	return SkipErrorsN(source, 0)
+}
+
+// SkipErrorsN works as SkipErrors, but after maxConsecutive errors in a row
+// the last one is returned and stops the iterator.
+// Non-positive maxConsecutive means no limit.
+func SkipErrorsN[T any](source Iterator[T], maxConsecutive int) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		for failed := 0; ; {
+			value, err := source()
+			if err == nil {
+				return value, nil
+			}
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				return empty, ErrStopIt
+			}
+			failed++
+			if maxConsecutive > 0 && failed >= maxConsecutive {
+				done = true
+				return empty, err
+			}
+		}
+	}
+}
+
+// WithIndex returns an iterator wrapping errors of the source
+// with the index of the failed element, counting from 0.
+// ErrStopIt is never wrapped.
+func WithIndex[T any](source Iterator[T]) Iterator[T] {
+	i := 0
+	return func() (T, error) {
+		value, err := source()
+		if err != nil && !errors.Is(err, ErrStopIt) {
+			err = fmt.Errorf("element %d: %w", i, err)
+		}
+		i++
+		return value, err
+	}
+}
+
+// Named returns an iterator wrapping errors of the source with the name of the stage.
+// ErrStopIt is never wrapped.
+func Named[T any](name string, source Iterator[T]) Iterator[T] {
+	return func() (T, error) {
+		value, err := source()
+		if err != nil && !errors.Is(err, ErrStopIt) {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		return value, err
+	}
+}
+
+// Fallback returns an iterator yielding value in place of every failed
+// element of the source, so the number of elements is preserved.
+func Fallback[T any](source Iterator[T], value T) Iterator[T] {
+	return FallbackFunc(source, func(error) (T, error) {
+		return value, nil
+	})
+}
+
+// FallbackFunc is Fallback with the substitute computed by fn from the error.
+// An error returned by fn is returned and stops the iterator.
+func FallbackFunc[T any](source Iterator[T], fn func(error) (T, error)) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrStopIt) {
+			value, err = fn(err)
+		}
+		if err != nil {
+			done = true
+			return empty, err
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,259 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+type report struct {
+	msg   string
+	count int
+}
+
+// timedSource yields the elements, nil errors are values, advancing the clock
+// by step before every element.
+func timedSource(clock *fakeClock, step time.Duration, elements []error) Iterator[int] {
+	i := 0
+	return func() (int, error) {
+		if i >= len(elements) {
+			return 0, ErrStopIt
+		}
+		clock.Advance(step)
+		i++
+		return i - 1, elements[i-1]
+	}
+}
+
+func coalesce(t *testing.T, clock *fakeClock, step, window time.Duration, elements []error) ([]int, []report) {
+	t.Helper()
+	var reports []report
+	it := CoalesceErrorsClock(timedSource(clock, step, elements), window, func(err error, count int) {
+		reports = append(reports, report{err.Error(), count})
+	}, clock)
+	values, err := ToSlice(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values, reports
+}
+
+func TestCoalesceErrorsValueEndsRun(t *testing.T) {
+	e1 := errors.New("e1")
+	values, reports := coalesce(t, newFakeClock(), time.Millisecond, time.Second,
+		[]error{e1, e1, e1, nil, e1})
+	if want := []int{3}; !slices.Equal(values, want) {
+		t.Fatalf("got values %v, want %v", values, want)
+	}
+	if want := []report{{"e1", 1}, {"e1", 2}, {"e1", 1}}; !slices.Equal(reports, want) {
+		t.Fatalf("got reports %v, want %v", reports, want)
+	}
+}
+
+func TestCoalesceErrorsWindow(t *testing.T) {
+	e1 := errors.New("e1")
+	elements := make([]error, 26)
+	for i := range elements {
+		elements[i] = e1
+	}
+	// Errors come every 10ms starting at 10ms, the window is 100ms.
+	_, reports := coalesce(t, newFakeClock(), 10*time.Millisecond, 100*time.Millisecond, elements)
+	if want := []report{{"e1", 1}, {"e1", 10}, {"e1", 10}, {"e1", 5}}; !slices.Equal(reports, want) {
+		t.Fatalf("got reports %v, want %v", reports, want)
+	}
+}
+
+func TestCoalesceErrorsIdentical(t *testing.T) {
+	e1 := errors.New("e1")
+	e2 := errors.New("e2")
+	_, reports := coalesce(t, newFakeClock(), time.Millisecond, time.Second, []error{
+		e1,
+		errors.New("e1"),
+		fmt.Errorf("wrapped: %w", e1),
+		e2,
+		e2,
+		e1,
+	})
+	if want := []report{{"e1", 1}, {"e1", 2}, {"e2", 1}, {"e2", 1}, {"e1", 1}}; !slices.Equal(reports, want) {
+		t.Fatalf("got reports %v, want %v", reports, want)
+	}
+}
+
+func TestCoalesceErrorsPassthrough(t *testing.T) {
+	reported := false
+	it := CoalesceErrorsClock(Range(0, 5, 1), time.Second, func(error, int) { reported = true }, newFakeClock())
+	got, err := ToSlice(it)
+	if err != nil || !slices.Equal(got, []int{0, 1, 2, 3, 4}) || reported {
+		t.Fatalf("got %v, %v, reported %v, want [0 1 2 3 4], nil, no reports", got, err, reported)
+	}
+}
+
+// failEvery returns the range of n elements with every k-th one failed.
+func failEvery(n, k int, err error) Iterator[int] {
+	return Map(Range(0, n, 1), func(v int) (int, error) {
+		if v%k == 0 {
+			return 0, err
+		}
+		return v, nil
+	})
+}
+
+func TestSkipErrors(t *testing.T) {
+	got, err := ToSlice(SkipErrors(failEvery(10, 3, errors.New("failure"))))
+	if want := []int{1, 2, 4, 5, 7, 8}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestSkipErrorsN(t *testing.T) {
+	failure := errors.New("failure")
+	got, err := ToSlice(SkipErrorsN(failEvery(10, 3, failure), 2))
+	if want := []int{1, 2, 4, 5, 7, 8}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	calls := 0
+	it := SkipErrorsN(func() (int, error) {
+		calls++
+		return 0, failure
+	}, 5)
+	if _, err := it(); !errors.Is(err, failure) || calls != 5 {
+		t.Fatalf("got %v after %d calls, want %v after 5", err, calls, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) || calls != 5 {
+		t.Fatalf("got %v after %d calls, want ErrStopIt and no more calls", err, calls)
+	}
+}
+
+func TestCatch(t *testing.T) {
+	failure := errors.New("failure")
+	var handled []error
+	got, err := ToSlice(Catch(failEvery(10, 3, failure), func(err error) error {
+		handled = append(handled, err)
+		return nil
+	}))
+	if want := []int{1, 2, 4, 5, 7, 8}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if len(handled) != 4 {
+		t.Fatalf("handle called %d times, want 4", len(handled))
+	}
+
+	it := Catch(failEvery(10, 3, failure), func(error) error { return ErrStopIt })
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt from handle to stop the iterator", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the stop, want ErrStopIt", err)
+	}
+
+	translated := errors.New("translated")
+	it = Catch(failEvery(10, 3, failure), func(err error) error { return fmt.Errorf("%w: %w", translated, err) })
+	if _, err := it(); !errors.Is(err, translated) || !errors.Is(err, failure) {
+		t.Fatalf("got %v, want the error of handle", err)
+	}
+	if v, err := it(); err != nil || v != 1 {
+		t.Fatalf("got %v, %v after the error, want 1, nil", v, err)
+	}
+
+	calls := 0
+	got, err = ToSlice(Catch(Range(0, 3, 1), func(error) error {
+		calls++
+		return nil
+	}))
+	if err != nil || len(got) != 3 || calls != 0 {
+		t.Fatalf("got %v, %v, handle called %d times, want 3 elements and no calls", got, err, calls)
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func TestWithIndexNamed(t *testing.T) {
+	it := Named("parse", WithIndex(failEvery(5, 2, &codeError{code: 7})))
+	var errs []error
+	for {
+		_, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	want := []string{"parse: element 0: code 7", "parse: element 2: code 7", "parse: element 4: code 7"}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want %v", errs, want)
+	}
+	for i, err := range errs {
+		var target *codeError
+		if err.Error() != want[i] || !errors.As(err, &target) || target.code != 7 {
+			t.Fatalf("got %v, want %s unwrapping to the code error", err, want[i])
+		}
+	}
+}
+
+func TestWithIndexNamedStop(t *testing.T) {
+	// ErrStopIt must be returned unwrapped, finalizers may compare it directly.
+	for name, it := range map[string]Iterator[int]{
+		"WithIndex": WithIndex(Range(0, 3, 1)),
+		"Named":     Named("stage", Range(0, 3, 1)),
+	} {
+		got, err := ToSlice(it)
+		if err != nil || len(got) != 3 {
+			t.Fatalf("%s: got %v, %v, want 3 elements, nil", name, got, err)
+		}
+		if _, err := it(); err != ErrStopIt {
+			t.Fatalf("%s: got %v, want the bare ErrStopIt", name, err)
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	failure := errors.New("failure")
+	got, err := ToSlice(Fallback(failEvery(7, 3, failure), -1))
+	// The length is preserved, so the result stays aligned with the source.
+	if want := []int{-1, 1, 2, -1, 4, 5, -1}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestFallbackFunc(t *testing.T) {
+	failure, fatal := errors.New("failure"), errors.New("fatal")
+	source := FromSlice([]error{nil, failure, nil, fatal, nil})
+	i := 0
+	it := FallbackFunc(Map(source, func(err error) (int, error) {
+		i++
+		return i, err
+	}), func(err error) (int, error) {
+		if errors.Is(err, fatal) {
+			return 0, err
+		}
+		return 0, nil
+	})
+	for _, want := range []int{1, 0, 3} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, fatal) {
+		t.Fatalf("got %v, want %v", err, fatal)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+	calls := 0
+	got, err := ToSlice(FallbackFunc(Range(0, 3, 1), func(error) (int, error) {
+		calls++
+		return 0, nil
+	}))
+	if err != nil || len(got) != 3 || calls != 0 {
+		t.Fatalf("got %v, %v, fn called %d times, want 3 elements and no calls", got, err, calls)
+	}
+}
@@ -0,0 +1,80 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+func ExampleRandomInts() {
+	// A seeded source gives the same rolls on every run,
+	// Limit makes the infinite iterator finite.
+	dice := Limit(RandomInts(rand.New(rand.NewSource(1)), 1, 7), 5)
+	rolls, err := ToSlice(dice)
+	fmt.Println(rolls, err)
+	// Output:
+	// [6 4 6 6 2] <nil>
+}
+
+func ExampleRandomChoice() {
+	moves := Limit(RandomChoice(rand.New(rand.NewSource(1)), "rock", "paper", "scissors"), 4)
+	chosen, err := ToSlice(moves)
+	fmt.Println(chosen, err)
+	// Output:
+	// [scissors rock scissors scissors] <nil>
+}
+
+func ExampleRandomFloats() {
+	values, err := ToSlice(Limit(RandomFloats(rand.New(rand.NewSource(1))), 2))
+	fmt.Printf("%.3f %v\n", values, err)
+	// Output:
+	// [0.605 0.941] <nil>
+}
+
+func ExampleLines() {
+	log := "INFO start\nERROR disk full\nINFO retry\r\nERROR disk full again\n"
+	lines := Lines(strings.NewReader(log))
+	errorsSeen := 0
+	for {
+		line, err := lines()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if strings.HasPrefix(line, "ERROR") {
+			errorsSeen++
+		}
+	}
+	fmt.Println(errorsSeen, "error lines")
+	// Output:
+	// 2 error lines
+}
+
+func ExampleFromJSONArray() {
+	type user struct {
+		Name   string `json:"name"`
+		Active bool   `json:"active"`
+	}
+	input := `[{"name": "ann", "active": true}, {"name": "bob"}, {"name": "cid", "active": true}]`
+	users := FromJSONArray[user](strings.NewReader(input))
+	for {
+		u, err := users()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if u.Active {
+			fmt.Println(u.Name)
+		}
+	}
+	// Output:
+	// ann
+	// cid
+}
@@ -0,0 +1,138 @@
+package iter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ExternalSort returns an iterator of elements of the source sorted by less
+// keeping at most memLimit elements in memory.
+//
+// The source is read in runs of memLimit elements, every run is sorted
+// and spilled to a temporary file in dir encoded with the codec, then the runs
+// are merged. The default directory for temporary files is used if dir is empty.
+// If the whole source fits into memLimit nothing is written to disk.
+// The sort is stable. Temporary files are removed when the iterator stops
+// or the returned close function is called, after that the iterator stops.
+// The close function must not be called concurrently with the iterator,
+// it's safe to call it several times. Non-positive memLimit results in an iterator returning ErrInvalidArgument.
+func ExternalSort[T any](source Iterator[T], less func(a, b T) bool, memLimit int, codec Codec[T], dir string) (Iterator[T], func()) {
+	if memLimit <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive memory limit %d", ErrInvalidArgument, memLimit)), func() {}
+	}
+	var (
+		sorted Iterator[T]
+		files  []*os.File
+		done   bool
+	)
+	cleanup := func() {
+		for _, f := range files {
+			f.Close()
+			os.Remove(f.Name())
+		}
+		files = nil
+	}
+	compare := func(a, b T) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	}
+	it := func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if sorted == nil {
+			var err error
+			sorted, files, err = spillRuns(source, less, memLimit, codec, dir)
+			if err != nil {
+				done = true
+				cleanup()
+				return empty, err
+			}
+			if len(files) > 0 {
+				runs := make([]Iterator[T], len(files))
+				for i, f := range files {
+					runs[i] = codec.Decoder(bufio.NewReader(f))
+				}
+				sorted = mergeSorted(compare, runs)
+			}
+		}
+		value, err := sorted()
+		if err != nil {
+			done = true
+			cleanup()
+		}
+		return value, err
+	}
+	return it, func() {
+		done = true
+		cleanup()
+	}
+}
+
+// spillRuns reads the source in sorted runs of memLimit elements and spills them
+// to temporary files opened for reading, the single run fitting into memory
+// is returned as an iterator without spilling.
+func spillRuns[T any](source Iterator[T], less func(a, b T) bool, memLimit int, codec Codec[T], dir string) (Iterator[T], []*os.File, error) {
+	var files []*os.File
+	run := make([]T, 0, memLimit)
+	for {
+		run = run[:0]
+		stopped := false
+		for len(run) < memLimit {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				stopped = true
+				break
+			}
+			if err != nil {
+				return nil, files, err
+			}
+			run = append(run, value)
+		}
+		sort.SliceStable(run, func(i, j int) bool { return less(run[i], run[j]) })
+		if stopped && len(files) == 0 {
+			return FromSlice(run), nil, nil
+		}
+		if len(run) > 0 {
+			f, err := spill(run, codec, dir)
+			if f != nil {
+				files = append(files, f)
+			}
+			if err != nil {
+				return nil, files, err
+			}
+		}
+		if stopped {
+			return nil, files, nil
+		}
+	}
+}
+
+// spill writes the run to a temporary file and rewinds it for reading.
+func spill[T any](run []T, codec Codec[T], dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "iter-spill-*")
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(f)
+	encode := codec.Encoder(writer)
+	for _, value := range run {
+		if err := encode(value); err != nil {
+			return f, err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return f, err
+	}
+	_, err = f.Seek(0, 0)
+	return f, err
+}
@@ -0,0 +1,110 @@
+package iter
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("%d temporary files left in %s", len(entries), dir)
+	}
+}
+
+func TestExternalSort(t *testing.T) {
+	const n = 300000
+	rng := rand.New(rand.NewSource(1))
+	values := make([]int, n)
+	counts := make(map[int]int)
+	for i := range values {
+		values[i] = rng.Intn(n / 2)
+		counts[values[i]]++
+	}
+	dir := t.TempDir()
+	it, _ := ExternalSort(FromSlice(values), func(a, b int) bool { return a < b }, 1000, GobCodec[int]{}, dir)
+	got, err := ToSlice(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != n {
+		t.Fatalf("got %d elements, want %d", len(got), n)
+	}
+	for i, v := range got {
+		if i > 0 && got[i-1] > v {
+			t.Fatalf("element %d: %d after %d", i, v, got[i-1])
+		}
+		counts[v]--
+	}
+	for v, c := range counts {
+		if c != 0 {
+			t.Fatalf("value %d: count differs by %d", v, c)
+		}
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestExternalSortStable(t *testing.T) {
+	values := make([]Pair[int, int], 1000)
+	for i := range values {
+		values[i] = Pair[int, int]{Left: i % 7, Right: i}
+	}
+	it, _ := ExternalSort(FromSlice(values), func(a, b Pair[int, int]) bool { return a.Left < b.Left },
+		10, JSONCodec[Pair[int, int]]{}, t.TempDir())
+	got, err := ToSlice(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Left == got[i].Left && got[i-1].Right > got[i].Right {
+			t.Fatalf("equal elements reordered: %v before %v", got[i-1], got[i])
+		}
+	}
+}
+
+func TestExternalSortClose(t *testing.T) {
+	dir := t.TempDir()
+	it, stop := ExternalSort(Range(10000, 0, -1), func(a, b int) bool { return a < b }, 100, GobCodec[int]{}, dir)
+	for i := 1; i <= 10; i++ {
+		if v, err := it(); err != nil || v != i {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+		}
+	}
+	stop()
+	assertEmptyDir(t, dir)
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after close, want ErrStopIt", err)
+	}
+	stop()
+}
+
+func TestExternalSortSourceError(t *testing.T) {
+	failure := errors.New("failure")
+	dir := t.TempDir()
+	source := Range(0, 500, 1)
+	it, _ := ExternalSort(func() (int, error) {
+		v, err := source()
+		if errors.Is(err, ErrStopIt) {
+			return 0, failure
+		}
+		return v, err
+	}, func(a, b int) bool { return a < b }, 100, GobCodec[int]{}, dir)
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestExternalSortInvalid(t *testing.T) {
+	it, stop := ExternalSort(Range(0, 3, 1), func(a, b int) bool { return a < b }, 0, GobCodec[int]{}, "")
+	defer stop()
+	if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,48 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// InjectFaults returns an iterator passing through elements of the source
+// but randomly replacing an element with err at errRate
+// and sleeping up to maxDelay before yielding an element at delayRate.
+//
+// It's intended for testing how consumers behave on a degraded source.
+// Injected errors are element-local, the iterator keeps working after them.
+// Faults are never injected after the source stops and ErrStopIt is never injected.
+// The random decisions are taken from r, so a seeded r gives reproducible faults,
+// nil r means the global source of math/rand.
+// Rates outside of [0, 1] including NaN, negative maxDelay or nil or ErrStopIt err
+// result in an iterator returning ErrInvalidArgument.
+func InjectFaults[T any](source Iterator[T], r *rand.Rand, errRate float64, delayRate float64, maxDelay time.Duration, err error) Iterator[T] {
+	switch {
+	case !(errRate >= 0 && errRate <= 1) || !(delayRate >= 0 && delayRate <= 1):
+		return Err[T](fmt.Errorf("%w: rates %v and %v must be in [0, 1]", ErrInvalidArgument, errRate, delayRate))
+	case maxDelay < 0:
+		return Err[T](fmt.Errorf("%w: negative delay %v", ErrInvalidArgument, maxDelay))
+	case err == nil || errors.Is(err, ErrStopIt):
+		return Err[T](fmt.Errorf("%w: injected error must be a failure", ErrInvalidArgument))
+	}
+	float, int63n := rand.Float64, rand.Int63n
+	if r != nil {
+		float, int63n = r.Float64, r.Int63n
+	}
+	return func() (T, error) {
+		value, sourceErr := source()
+		if sourceErr != nil {
+			return value, sourceErr
+		}
+		if errRate > 0 && float() < errRate {
+			var empty T
+			return empty, err
+		}
+		if delayRate > 0 && float() < delayRate && maxDelay > 0 {
+			time.Sleep(time.Duration(int63n(int64(maxDelay) + 1)))
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,69 @@
+package iter
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInjectFaultsPositions(t *testing.T) {
+	failure := errors.New("failure")
+	for run := 0; run < 2; run++ {
+		it := InjectFaults(Range(0, 30, 1), rand.New(rand.NewSource(42)), 0.2, 0, 0, failure)
+		var values, failed []int
+		for i := 0; ; i++ {
+			v, err := it()
+			if errors.Is(err, ErrStopIt) {
+				break
+			}
+			if errors.Is(err, failure) {
+				failed = append(failed, i)
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, v)
+		}
+		if want := []int{1, 4, 13, 20, 25, 27}; !slices.Equal(failed, want) {
+			t.Fatalf("run %d: errors injected at %v, want %v", run, failed, want)
+		}
+		if len(values)+len(failed) != 30 {
+			t.Fatalf("run %d: got %d values and %d errors, want 30 elements", run, len(values), len(failed))
+		}
+	}
+}
+
+func TestInjectFaultsPassthrough(t *testing.T) {
+	it := InjectFaults(Range(0, 100, 1), rand.New(rand.NewSource(1)), 0, 0, time.Hour, errors.New("failure"))
+	got, err := ToSlice(it)
+	if err != nil || len(got) != 100 {
+		t.Fatalf("got %d elements, %v, want 100, nil", len(got), err)
+	}
+}
+
+func TestInjectFaultsInvalid(t *testing.T) {
+	failure := errors.New("failure")
+	for _, tc := range []struct {
+		errRate, delayRate float64
+		maxDelay           time.Duration
+		err                error
+	}{
+		{-0.1, 0, 0, failure},
+		{1.1, 0, 0, failure},
+		{0, 2, 0, failure},
+		{math.NaN(), 0, 0, failure},
+		{0, math.NaN(), 0, failure},
+		{0, 0, -time.Second, failure},
+		{0, 0, 0, nil},
+		{0, 0, 0, ErrStopIt},
+	} {
+		it := InjectFaults(Range(0, 3, 1), nil, tc.errRate, tc.delayRate, tc.maxDelay, tc.err)
+		if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%+v: got %v, want ErrInvalidArgument", tc, err)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package iter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// CountDistinct consumes the iterator and returns the number of distinct elements.
+// Every distinct element is kept in memory, see CountDistinctApprox for big streams.
+func CountDistinct[T comparable](it Iterator[T]) (int, error) {
+	seen := make(map[T]struct{})
+	for {
+		value, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return len(seen), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		seen[value] = struct{}{}
+	}
+}
+
+// Supported precision range of CountDistinctApprox.
+const (
+	MinDistinctPrecision = 4
+	MaxDistinctPrecision = 18
+)
+
+// CountDistinctApprox consumes the iterator and estimates the number of distinct
+// elements with a HyperLogLog sketch of 2^precision one-byte registers.
+//
+// The relative standard error of the estimate is about 1.04/sqrt(2^precision):
+// 26% for precision 4, 1.6% for precision 12 (4KB of registers)
+// and 0.2% for precision 18 (256KB of registers).
+// Precision outside of [MinDistinctPrecision, MaxDistinctPrecision]
+// results in ErrInvalidArgument.
+func CountDistinctApprox[T comparable](it Iterator[T], precision int) (int64, error) {
+	if precision < MinDistinctPrecision || precision > MaxDistinctPrecision {
+		return 0, fmt.Errorf("%w: precision %d is out of range [%d, %d]",
+			ErrInvalidArgument, precision, MinDistinctPrecision, MaxDistinctPrecision)
+	}
+	seed := maphash.MakeSeed()
+	registers := make([]uint8, 1<<precision)
+	for {
+		value, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		hash := maphash.Comparable(seed, value)
+		index := hash >> (64 - precision)
+		rank := uint8(bits.LeadingZeros64(hash<<precision|1<<(precision-1)) + 1)
+		if rank > registers[index] {
+			registers[index] = rank
+		}
+	}
+	return hllEstimate(registers), nil
+}
+
+// hllEstimate calculates the HyperLogLog cardinality estimate of the registers.
+func hllEstimate(registers []uint8) int64 {
+	m := float64(len(registers))
+	var alpha float64
+	switch len(registers) {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	default:
+		alpha = 0.7213 / (1 + 1.079/m)
+	}
+	sum, zeros := 0.0, 0
+	for _, r := range registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more precise for small cardinalities.
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
+
+// ForEachCtx consumes the iterator calling fn for every element.
+//
+// The context is checked before every element. The checkpoint callback
+// is called with the number of processed elements after every checkpointEvery
+// elements and once more when the iterator is exhausted,
+// non-positive checkpointEvery disables the periodic calls, nil checkpoint disables all of them.
+// ForEachCtx stops on the first error returned by the context, the iterator,
+// fn or checkpoint and returns it, nil is returned if the iterator is exhausted.
+func ForEachCtx[T any](ctx context.Context, it Iterator[T], fn func(context.Context, T) error,
+	checkpointEvery int, checkpoint func(processed int) error) error {
+	processed, checkpointed := 0, -1
+	call := func() error {
+		if checkpoint == nil || checkpointed == processed {
+			return nil
+		}
+		checkpointed = processed
+		return checkpoint(processed)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		value, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return call()
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(ctx, value); err != nil {
+			return err
+		}
+		processed++
+		if checkpointEvery > 0 && processed%checkpointEvery == 0 {
+			if err := call(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Compare compares two iterators lexicographically and returns
+// -1 if a is less than b, 0 if they are equal and +1 if a is greater than b.
+// An iterator being a prefix of the other one is less than the other one.
+// Iterators are consumed only up to the first differing element.
+func Compare[T cmp.Ordered](a, b Iterator[T]) (int, error) {
+	return CompareFunc(a, b, cmp.Compare[T])
+}
+
+// CompareFunc works as Compare using the comparison function fn.
+func CompareFunc[T any](a, b Iterator[T], fn func(a, b T) int) (int, error) {
+	for {
+		left, err := a()
+		leftDone := errors.Is(err, ErrStopIt)
+		if err != nil && !leftDone {
+			return 0, err
+		}
+		right, err := b()
+		rightDone := errors.Is(err, ErrStopIt)
+		if err != nil && !rightDone {
+			return 0, err
+		}
+		switch {
+		case leftDone && rightDone:
+			return 0, nil
+		case leftDone:
+			return -1, nil
+		case rightDone:
+			return 1, nil
+		}
+		if c := fn(left, right); c != 0 {
+			return max(-1, min(c, 1)), nil
+		}
+	}
+}
+
+// Aggregate holds streaming aggregates of a sequence of numbers.
+type Aggregate struct {
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+func (a *Aggregate) add(value float64) {
+	if a.Count == 0 {
+		a.Min, a.Max = value, value
+	}
+	a.Count++
+	a.Sum += value
+	a.Min = math.Min(a.Min, value)
+	a.Max = math.Max(a.Max, value)
+	a.Mean = a.Sum / float64(a.Count)
+}
+
+// AggregateByKey consumes the iterator and returns aggregates of values for every key.
+// Only the aggregates are kept in memory. If the iterator fails
+// the partial result is discarded and the error is returned.
+func AggregateByKey[K comparable](it Iterator[Pair[K, float64]]) (map[K]Aggregate, error) {
+	aggregates := make(map[K]*Aggregate)
+	for {
+		pair, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		aggregate, ok := aggregates[pair.Left]
+		if !ok {
+			aggregate = &Aggregate{}
+			aggregates[pair.Left] = aggregate
+		}
+		aggregate.add(pair.Right)
+	}
+	result := make(map[K]Aggregate, len(aggregates))
+	for k, aggregate := range aggregates {
+		result[k] = *aggregate
+	}
+	return result, nil
+}
+
+// ToSlice consumes the iterator and returns its elements.
+// If the iterator fails nil and the error are returned.
+func ToSlice[T any](it Iterator[T]) ([]T, error) {
+	var values []T
+	for {
+		value, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// DrainOnCancel consumes the iterator calling process for every element
+// until the context is cancelled, then it stops processing and hands the elements
+// already prefetched by Buffer stages created with the same context to onRemaining,
+// so they can be logged or persisted. The element pulled when the cancellation
+// is noticed is handed to onRemaining too. No new elements are pulled from
+// the sources, so the number of processed and remaining elements is a resumption point.
+//
+// The draining lasts at most timeout, a pull blocked longer than that is abandoned
+// in its goroutine. It returns the context error if the context is cancelled,
+// otherwise the first error of the iterator or process, or nil when the iterator stops.
+func DrainOnCancel[T any](ctx context.Context, it Iterator[T], process func(T) error, onRemaining func(T), timeout time.Duration) error {
+	holders := heldBy(ctx)
+	for {
+		if ctx.Err() != nil {
+			break
+		}
+		value, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			onRemaining(value)
+			break
+		}
+		if err := process(value); err != nil {
+			return err
+		}
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	held := func() int {
+		n := 0
+		for _, h := range holders {
+			n += h.held()
+		}
+		return n
+	}
+	for _, h := range holders {
+		select {
+		case <-h.settled:
+		case <-deadline.C:
+			return ctx.Err()
+		}
+	}
+	// Pulls stop at the stages which no longer hold elements,
+	// as they don't pull their sources after the cancellation.
+	for held() > 0 {
+		pulled := make(chan result[T], 1)
+		go func() {
+			value, err := it()
+			pulled <- result[T]{value, err}
+		}()
+		select {
+		case r := <-pulled:
+			if r.err != nil {
+				return ctx.Err()
+			}
+			onRemaining(r.value)
+		case <-deadline.C:
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// ConsumeWithCancel works as DrainOnCancel reporting only the number
+// of the remaining elements to remaining, it's called once when the context is cancelled.
+func ConsumeWithCancel[T any](ctx context.Context, it Iterator[T], process func(T) error, remaining func(count int), timeout time.Duration) error {
+	count := 0
+	err := DrainOnCancel(ctx, it, process, func(T) { count++ }, timeout)
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		remaining(count)
+	}
+	return err
+}
+
+// ForEachConcurrent consumes the iterator calling fn for every element
+// on several workers concurrently, the iterator is never called concurrently.
+//
+// After the first error of the context, the iterator or fn no new elements
+// are pulled, the context passed to fn is cancelled, and ForEachConcurrent
+// returns that error once the calls in flight finish, later errors are dropped.
+// nil is returned if the iterator is exhausted.
+// Non-positive number of workers results in ErrInvalidArgument.
+func ForEachConcurrent[T any](ctx context.Context, it Iterator[T], workers int, fn func(context.Context, T) error) error {
+	if workers <= 0 {
+		return fmt.Errorf("%w: non-positive number of workers %d", ErrInvalidArgument, workers)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Pulls are serialized by pullMu, the first error is recorded under errMu,
+	// so a failing worker cancels the others without waiting for a pull.
+	var (
+		pullMu sync.Mutex
+		errMu  sync.Mutex
+		first  error
+		wg     sync.WaitGroup
+	)
+	fail := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		if first == nil {
+			first = err
+			cancel()
+		}
+	}
+	next := func() (T, bool) {
+		pullMu.Lock()
+		defer pullMu.Unlock()
+		var empty T
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			return empty, false
+		}
+		value, err := it()
+		if err != nil {
+			if !errors.Is(err, ErrStopIt) {
+				fail(err)
+			}
+			return empty, false
+		}
+		return value, true
+	}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				value, ok := next()
+				if !ok {
+					return
+				}
+				if err := fn(ctx, value); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return first
+}
@@ -0,0 +1,491 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConsumeWithCancelBufferInChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var pulled atomic.Int64
+	source := Map(Range(0, 1000, 1), func(v int) (int, error) {
+		pulled.Add(1)
+		return v, nil
+	})
+	it := Map(Buffer(ctx, source, 10), func(v int) (int, error) { return v * 2, nil })
+	processed := 0
+	count := -1
+	err := ConsumeWithCancel(ctx, it, func(v int) error {
+		if v != processed*2 {
+			t.Errorf("got %d, want %d", v, processed*2)
+		}
+		processed++
+		if processed == 5 {
+			// Let the buffer fill up before cancelling.
+			deadline := time.Now().Add(time.Second)
+			for pulled.Load() < int64(processed+10+1) && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}
+		return nil
+	}, func(n int) { count = n }, time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if processed != 5 {
+		t.Fatalf("processed %d elements, want 5", processed)
+	}
+	if want := int(pulled.Load()) - processed; count != want {
+		t.Fatalf("got %d remaining, want %d prefetched but unprocessed", count, want)
+	}
+}
+
+func TestDrainOnCancelHandsOffPrefetched(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var pulled atomic.Int64
+	source := Map(Range(0, 1000, 1), func(v int) (int, error) {
+		pulled.Add(1)
+		return v, nil
+	})
+	it := Buffer(ctx, source, 4)
+	next := 0
+	err := DrainOnCancel(ctx, it, func(v int) error {
+		next = v + 1
+		if v == 2 {
+			deadline := time.Now().Add(time.Second)
+			for pulled.Load() < 3+4+1 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}
+		return nil
+	}, func(v int) {
+		if v != next {
+			t.Errorf("got remaining %d, want %d", v, next)
+		}
+		next++
+	}, time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if int64(next) != pulled.Load() {
+		t.Fatalf("handed off up to %d, but %d elements were pulled", next, pulled.Load())
+	}
+}
+
+func TestConsumeWithCancelWithoutBuffer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var pulled atomic.Int64
+	source := Map(Range(0, 1000, 1), func(v int) (int, error) {
+		pulled.Add(1)
+		return v, nil
+	})
+	count := -1
+	err := ConsumeWithCancel(ctx, source, func(v int) error {
+		if v == 9 {
+			cancel()
+		}
+		return nil
+	}, func(n int) { count = n }, time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if count != 0 || pulled.Load() != 10 {
+		t.Fatalf("got %d remaining after %d pulls, want 0 after 10", count, pulled.Load())
+	}
+}
+
+func TestConsumeWithCancelErrors(t *testing.T) {
+	failure := errors.New("failure")
+	called := false
+	err := ConsumeWithCancel(context.Background(), Range(0, 10, 1), func(v int) error {
+		if v == 3 {
+			return failure
+		}
+		return nil
+	}, func(int) { called = true }, time.Second)
+	if !errors.Is(err, failure) || called {
+		t.Fatalf("got %v, remaining called %v, want %v and no call", err, called, failure)
+	}
+	if err := ConsumeWithCancel(context.Background(), Range(0, 10, 1), func(int) error { return nil },
+		func(int) { called = true }, time.Second); err != nil || called {
+		t.Fatalf("got %v, remaining called %v, want nil and no call", err, called)
+	}
+}
+
+func TestCountDistinct(t *testing.T) {
+	got, err := CountDistinct(FromSlice([]int{1, 2, 2, 3, 1, 1}))
+	if err != nil || got != 3 {
+		t.Fatalf("got %d, %v, want 3, nil", got, err)
+	}
+	failure := errors.New("failure")
+	if _, err := CountDistinct(Err[int](failure)); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestCountDistinctApproxTolerance(t *testing.T) {
+	for _, tc := range []struct {
+		distinct  int
+		precision int
+	}{
+		{100, 10},
+		{10000, 10},
+		{10000, 14},
+		{200000, 12},
+	} {
+		// Every value is repeated, duplicates must not affect the estimate.
+		it := Map(Range(0, tc.distinct*3, 1), func(v int) (int, error) { return v % tc.distinct, nil })
+		got, err := CountDistinctApprox(it, tc.precision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exact, _ := CountDistinct(Map(Range(0, tc.distinct*3, 1), func(v int) (int, error) { return v % tc.distinct, nil }))
+		stdErr := 1.04 / math.Sqrt(float64(int(1)<<tc.precision))
+		if diff := math.Abs(float64(got)-float64(exact)) / float64(exact); diff > 4*stdErr {
+			t.Errorf("distinct %d, precision %d: got %d, relative error %.4f exceeds %.4f",
+				tc.distinct, tc.precision, got, diff, 4*stdErr)
+		}
+	}
+}
+
+func TestCountDistinctApproxEqualKeys(t *testing.T) {
+	type point struct {
+		x, y float64
+	}
+	floats := []float64{0, math.Copysign(0, -1), 1, -1, 0.5}
+	var points []point
+	for i := 0; i < 300; i++ {
+		points = append(points, point{float64(i % 100), math.Copysign(0, float64(i/100%2*2-1))})
+	}
+	exactFloats, _ := CountDistinct(FromSlice(floats))
+	if got, err := CountDistinctApprox(FromSlice(floats), MaxDistinctPrecision); err != nil || got != int64(exactFloats) {
+		t.Fatalf("got %d, %v for floats, want %d, nil", got, err, exactFloats)
+	}
+	// Hashing the zeros differently would double the estimate,
+	// registers colliding by chance may lose a few elements.
+	exactPoints, _ := CountDistinct(FromSlice(points))
+	if got, err := CountDistinctApprox(FromSlice(points), MaxDistinctPrecision); err != nil || got > int64(exactPoints) || got < int64(exactPoints)-3 {
+		t.Fatalf("got %d, %v for structs, want about %d, nil", got, err, exactPoints)
+	}
+}
+
+func TestCountDistinctApproxInvalid(t *testing.T) {
+	for _, precision := range []int{MinDistinctPrecision - 1, MaxDistinctPrecision + 1} {
+		if _, err := CountDistinctApprox(Range(0, 10, 1), precision); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("precision %d: got %v, want ErrInvalidArgument", precision, err)
+		}
+	}
+}
+
+func TestForEachConcurrent(t *testing.T) {
+	var sum atomic.Int64
+	err := ForEachConcurrent(context.Background(), Range(0, 1000, 1), 8, func(_ context.Context, v int) error {
+		sum.Add(int64(v))
+		return nil
+	})
+	if err != nil || sum.Load() != 999*1000/2 {
+		t.Fatalf("got %d, %v, want %d, nil", sum.Load(), err, 999*1000/2)
+	}
+}
+
+func TestForEachConcurrentCancelsWhilePullBlocked(t *testing.T) {
+	failure := errors.New("failure")
+	blocked := make(chan struct{})
+	block := make(chan struct{})
+	calls := 0
+	source := func() (int, error) {
+		calls++
+		if calls <= 2 {
+			return calls - 1, nil
+		}
+		if calls == 3 {
+			close(blocked)
+			<-block
+		}
+		return 0, ErrStopIt
+	}
+	cancelled := make(chan bool, 1)
+	finished := make(chan error, 1)
+	go func() {
+		finished <- ForEachConcurrent(context.Background(), source, 3, func(ctx context.Context, v int) error {
+			if v == 1 {
+				<-blocked
+				return failure
+			}
+			select {
+			case <-ctx.Done():
+				cancelled <- true
+			case <-time.After(time.Second):
+				cancelled <- false
+			}
+			return nil
+		})
+	}()
+	if !<-cancelled {
+		t.Error("the context wasn't cancelled while another worker was blocked in a pull")
+	}
+	close(block)
+	if err := <-finished; !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestForEachConcurrentWaitsInFlight(t *testing.T) {
+	failure := errors.New("failure")
+	var running, finished atomic.Int64
+	err := ForEachConcurrent(context.Background(), Range(0, 100, 1), 4, func(ctx context.Context, v int) error {
+		running.Add(1)
+		defer finished.Add(1)
+		if v == 3 {
+			return failure
+		}
+		<-ctx.Done()
+		return errors.New("later failure")
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if running.Load() != finished.Load() {
+		t.Fatalf("returned with %d of %d calls in flight", running.Load()-finished.Load(), running.Load())
+	}
+}
+
+func TestForEachConcurrentContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	err := ForEachConcurrent(ctx, Range(0, 1000000, 1), 4, func(context.Context, int) error {
+		if calls.Add(1) == 10 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if n := calls.Load(); n > 10+4 {
+		t.Fatalf("fn called %d times after cancellation at 10", n)
+	}
+}
+
+func TestForEachConcurrentErrors(t *testing.T) {
+	failure := errors.New("failure")
+	err := ForEachConcurrent(context.Background(), Err[int](failure), 2, func(context.Context, int) error { return nil })
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	err = ForEachConcurrent(context.Background(), Range(0, 3, 1), 0, func(context.Context, int) error { return nil })
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestForEachCtxCheckpoints(t *testing.T) {
+	var checkpoints []int
+	err := ForEachCtx(context.Background(), Range(0, 7, 1), func(context.Context, int) error { return nil },
+		3, func(processed int) error {
+			checkpoints = append(checkpoints, processed)
+			return nil
+		})
+	if want := []int{3, 6, 7}; err != nil || !slices.Equal(checkpoints, want) {
+		t.Fatalf("got checkpoints %v, %v, want %v, nil", checkpoints, err, want)
+	}
+	// The final checkpoint isn't repeated when it falls on a periodic one.
+	checkpoints = nil
+	err = ForEachCtx(context.Background(), Range(0, 6, 1), func(context.Context, int) error { return nil },
+		3, func(processed int) error {
+			checkpoints = append(checkpoints, processed)
+			return nil
+		})
+	if want := []int{3, 6}; err != nil || !slices.Equal(checkpoints, want) {
+		t.Fatalf("got checkpoints %v, %v, want %v, nil", checkpoints, err, want)
+	}
+}
+
+func TestForEachCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var processed, checkpoints []int
+	err := ForEachCtx(ctx, Range(0, 100, 1), func(_ context.Context, v int) error {
+		processed = append(processed, v)
+		if v == 4 {
+			cancel()
+		}
+		return nil
+	}, 2, func(n int) error {
+		checkpoints = append(checkpoints, n)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if len(processed) != 5 {
+		t.Fatalf("processed %v, want 5 elements", processed)
+	}
+	if want := []int{2, 4}; !slices.Equal(checkpoints, want) {
+		t.Fatalf("got checkpoints %v, want %v without the final one", checkpoints, want)
+	}
+}
+
+func TestForEachCtxCheckpointError(t *testing.T) {
+	failure := errors.New("failure")
+	processed := 0
+	err := ForEachCtx(context.Background(), Range(0, 100, 1), func(context.Context, int) error {
+		processed++
+		return nil
+	}, 10, func(n int) error {
+		if n == 20 {
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) || processed != 20 {
+		t.Fatalf("got %v after %d elements, want %v after 20", err, processed, failure)
+	}
+	err = ForEachCtx(context.Background(), Range(0, 5, 1), func(context.Context, int) error { return nil },
+		0, func(n int) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v for the final checkpoint, want %v", err, failure)
+	}
+}
+
+func TestForEachCtxFnError(t *testing.T) {
+	failure := errors.New("failure")
+	called := false
+	err := ForEachCtx(context.Background(), Range(0, 5, 1), func(_ context.Context, v int) error {
+		if v == 2 {
+			return failure
+		}
+		return nil
+	}, 0, func(int) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, failure) || called {
+		t.Fatalf("got %v, checkpoint called %v, want %v and no checkpoint", err, called, failure)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, tc := range []struct {
+		a, b []int
+		want int
+	}{
+		{[]int{1, 2, 3}, []int{1, 2, 3}, 0},
+		{nil, nil, 0},
+		{[]int{1, 2}, []int{1, 2, 3}, -1},
+		{[]int{1, 2, 3}, []int{1, 2}, 1},
+		{nil, []int{1}, -1},
+		{[]int{1, 3}, []int{1, 2, 9}, 1},
+		{[]int{1, 2, 9}, []int{1, 3}, -1},
+	} {
+		if got, err := Compare(FromSlice(tc.a), FromSlice(tc.b)); err != nil || got != tc.want {
+			t.Errorf("%v and %v: got %d, %v, want %d, nil", tc.a, tc.b, got, err, tc.want)
+		}
+	}
+}
+
+func TestCompareInfinite(t *testing.T) {
+	// Infinite sources are consumed only up to the first difference.
+	pulls := 0
+	a := Iterate(0, func(v int) int {
+		pulls++
+		return v + 1
+	})
+	b := Map(Iterate(0, func(v int) int { return v + 1 }), func(v int) (int, error) {
+		if v == 100 {
+			return -1, nil
+		}
+		return v, nil
+	})
+	if got, err := Compare(a, b); err != nil || got != 1 {
+		t.Fatalf("got %d, %v, want 1, nil", got, err)
+	}
+	if pulls > 101 {
+		t.Fatalf("pulled %d elements past the divergence at 100", pulls-100)
+	}
+}
+
+func TestCompareError(t *testing.T) {
+	failure := errors.New("failure")
+	if _, err := Compare(Range(0, 3, 1), Err[int](failure)); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := Compare(Err[int](failure), Range(0, 3, 1)); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestCompareFunc(t *testing.T) {
+	byLen := func(a, b string) int { return len(a) - len(b) }
+	got, err := CompareFunc(FromSlice([]string{"aa", "b"}), FromSlice([]string{"xx", "yyy"}), byLen)
+	if err != nil || got >= 0 {
+		t.Fatalf("got %d, %v, want a negative result, nil", got, err)
+	}
+}
+
+func TestAggregateByKey(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var pairs []Pair[int, float64]
+	groups := make(map[int][]float64)
+	for i := 0; i < 10000; i++ {
+		k, v := rng.Intn(50), rng.NormFloat64()*100
+		pairs = append(pairs, Pair[int, float64]{k, v})
+		groups[k] = append(groups[k], v)
+	}
+	got, err := AggregateByKey(FromSlice(pairs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(groups) {
+		t.Fatalf("got %d keys, want %d", len(got), len(groups))
+	}
+	for k, values := range groups {
+		want := Aggregate{Count: len(values), Min: values[0], Max: values[0]}
+		for _, v := range values {
+			want.Sum += v
+			want.Min = math.Min(want.Min, v)
+			want.Max = math.Max(want.Max, v)
+		}
+		want.Mean = want.Sum / float64(want.Count)
+		if got[k] != want {
+			t.Fatalf("key %d: got %+v, want %+v", k, got[k], want)
+		}
+	}
+}
+
+func TestAggregateByKeyEdgeValues(t *testing.T) {
+	got, err := AggregateByKey(FromSlice([]Pair[string, float64]{
+		{"a", math.Inf(1)}, {"a", -1}, {"b", math.MaxFloat64}, {"b", -math.MaxFloat64}, {"c", 0},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := got["a"]; a.Count != 2 || a.Min != -1 || !math.IsInf(a.Max, 1) || !math.IsInf(a.Mean, 1) {
+		t.Fatalf("got %+v for a", a)
+	}
+	if b := got["b"]; b.Sum != 0 || b.Min != -math.MaxFloat64 || b.Max != math.MaxFloat64 {
+		t.Fatalf("got %+v for b", b)
+	}
+	if c := got["c"]; c != (Aggregate{Count: 1}) {
+		t.Fatalf("got %+v for c", c)
+	}
+}
+
+func TestAggregateByKeyError(t *testing.T) {
+	failure := errors.New("failure")
+	got, err := AggregateByKey(Err[Pair[string, float64]](failure))
+	if !errors.Is(err, failure) || got != nil {
+		t.Fatalf("got %v, %v, want nil, %v", got, err, failure)
+	}
+}
@@ -0,0 +1,264 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync/atomic"
+)
+
+// CycleIterator returns an iterator that yields elements of the source
+// and, once the source is exhausted, replays them indefinitely.
+// Elements are recorded during the first pass, so memory grows with the source.
+//
+// An empty source makes the iterator stop immediately.
+// An error during the first pass is returned on every following call.
+func CycleIterator[T any](source Iterator[T]) Iterator[T] {
+	var recorded []T
+	var err error
+	replay := false
+	i := 0
+	return func() (T, error) {
+		var empty T
+		if err != nil {
+			return empty, err
+		}
+		if !replay {
+			value, nextErr := source()
+			if nextErr == nil {
+				recorded = append(recorded, value)
+				return value, nil
+			}
+			if !errors.Is(nextErr, ErrStopIt) {
+				err = nextErr
+				return empty, err
+			}
+			replay = true
+		}
+		if len(recorded) == 0 {
+			return empty, ErrStopIt
+		}
+		value := recorded[i]
+		i = (i + 1) % len(recorded)
+		return value, nil
+	}
+}
+
+// CycleIteratorSafe returns a thread-safe version of CycleIterator.
+func CycleIteratorSafe[T any](source Iterator[T]) Iterator[T] {
+	return safe(CycleIterator(source))
+}
+
+// RepeatN returns an iterator that yields value n times,
+// n <= 0 makes it an Empty iterator.
+func RepeatN[T any](value T, n int) Iterator[T] {
+	return func() (T, error) {
+		if n <= 0 {
+			var empty T
+			return empty, ErrStopIt
+		}
+		n--
+		return value, nil
+	}
+}
+
+// RepeatNSafe returns a thread-safe version of RepeatN,
+// concurrent callers receive exactly n values in total.
+func RepeatNSafe[T any](value T, n int) Iterator[T] {
+	var left atomic.Int64
+	left.Store(int64(n))
+	return func() (T, error) {
+		if left.Add(-1) < 0 {
+			var empty T
+			return empty, ErrStopIt
+		}
+		return value, nil
+	}
+}
+
+// Once returns an iterator that yields value a single time.
+func Once[T any](value T) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		if done {
+			var empty T
+			return empty, ErrStopIt
+		}
+		done = true
+		return value, nil
+	}
+}
+
+// OnceSafe returns a thread-safe version of Once,
+// only one of concurrent callers receives the value.
+func OnceSafe[T any](value T) Iterator[T] {
+	var done atomic.Bool
+	return func() (T, error) {
+		if done.Swap(true) {
+			var empty T
+			return empty, ErrStopIt
+		}
+		return value, nil
+	}
+}
+
+// rangeLen returns the number of values in the range, step must not be 0.
+// It's calculated in uint, the distance between start and stop may overflow int.
+func rangeLen(start, stop, step int) uint {
+	switch {
+	case step > 0 && start < stop:
+		return (uint(stop)-uint(start)-1)/uint(step) + 1
+	case step < 0 && start > stop:
+		return (uint(start)-uint(stop)-1)/-uint(step) + 1
+	default:
+		return 0
+	}
+}
+
+// Range returns an iterator of start, start+step, ... up to stop exclusively,
+// like range in Python. The iterator is empty if stop can't be reached
+// in the direction of step. Zero step or a range of more than math.MaxInt
+// values returns an error iterator with ErrInvalidArgument.
+func Range(start, stop, step int) Iterator[int] {
+	if step == 0 {
+		return Err[int](fmt.Errorf("%w: range step is 0", ErrInvalidArgument))
+	}
+	if rangeLen(start, stop, step) > math.MaxInt {
+		return Err[int](fmt.Errorf("%w: range [%d, %d) by %d is too long", ErrInvalidArgument, start, stop, step))
+	}
+	n := int(rangeLen(start, stop, step))
+	i := 0
+	return func() (int, error) {
+		if i >= n {
+			return 0, ErrStopIt
+		}
+		i++
+		return start + (i-1)*step, nil
+	}
+}
+
+// RangeSafe returns a thread-safe version of Range,
+// every value is received by exactly one of concurrent callers.
+func RangeSafe(start, stop, step int) Iterator[int] {
+	if step == 0 {
+		return Err[int](fmt.Errorf("%w: range step is 0", ErrInvalidArgument))
+	}
+	if rangeLen(start, stop, step) > math.MaxInt {
+		return Err[int](fmt.Errorf("%w: range [%d, %d) by %d is too long", ErrInvalidArgument, start, stop, step))
+	}
+	n := int64(rangeLen(start, stop, step))
+	var next atomic.Int64
+	return func() (int, error) {
+		i := next.Add(1) - 1
+		if i >= n {
+			return 0, ErrStopIt
+		}
+		return start + int(i)*step, nil
+	}
+}
+
+// Iterate returns an infinite iterator of seed, fn(seed), fn(fn(seed)), ...
+func Iterate[T any](seed T, fn func(T) T) Iterator[T] {
+	return IterateErr(seed, func(value T) (T, error) {
+		return fn(value), nil
+	})
+}
+
+// IterateErr is Iterate with fn that can fail.
+//
+// The iterator stops if fn returns ErrStopIt.
+// Any other error from fn is returned and stops the iterator.
+func IterateErr[T any](seed T, fn func(T) (T, error)) Iterator[T] {
+	value := seed
+	started := false
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started = true
+			return value, nil
+		}
+		next, err := fn(value)
+		if err != nil {
+			done = true
+			if errors.Is(err, ErrStopIt) {
+				return empty, ErrStopIt
+			}
+			return empty, err
+		}
+		value = next
+		return value, nil
+	}
+}
+
+// IterateSafe returns a thread-safe version of Iterate,
+// fn is never called concurrently, so no state is skipped or repeated.
+func IterateSafe[T any](seed T, fn func(T) T) Iterator[T] {
+	return safe(Iterate(seed, fn))
+}
+
+// RandomInts returns an infinite iterator of random integers in [min, max)
+// taken from rng, nil rng means the global source of math/rand.
+// Use Limit to get a finite number of values.
+//
+// min >= max results in an iterator returning ErrInvalidArgument.
+func RandomInts(rng *rand.Rand, min, max int) Iterator[int] {
+	if min >= max {
+		return Err[int](fmt.Errorf("%w: empty interval [%d, %d)", ErrInvalidArgument, min, max))
+	}
+	intn, uint64n := rand.Intn, rand.Uint64
+	if rng != nil {
+		intn, uint64n = rng.Intn, rng.Uint64
+	}
+	// max-min overflows int for intervals wider than math.MaxInt,
+	// but not uint, values are drawn from the whole uint range for them then.
+	width := uint(max) - uint(min)
+	if width > math.MaxInt {
+		return func() (int, error) {
+			for {
+				// More than half of the values are accepted.
+				if v := uint(uint64n()); v < width {
+					return int(uint(min) + v), nil
+				}
+			}
+		}
+	}
+	return func() (int, error) {
+		return min + intn(int(width)), nil
+	}
+}
+
+// RandomFloats returns an infinite iterator of random floats in [0.0, 1.0)
+// taken from rng, nil rng means the global source of math/rand.
+// Use Limit to get a finite number of values.
+func RandomFloats(rng *rand.Rand) Iterator[float64] {
+	float := rand.Float64
+	if rng != nil {
+		float = rng.Float64
+	}
+	return func() (float64, error) {
+		return float(), nil
+	}
+}
+
+// RandomChoice returns an infinite iterator of values picked at random
+// with rng, nil rng means the global source of math/rand.
+// Use Limit to get a finite number of values.
+//
+// No values make it an Empty iterator.
+func RandomChoice[T any](rng *rand.Rand, values ...T) Iterator[T] {
+	if len(values) == 0 {
+		return Empty[T]()
+	}
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+	return func() (T, error) {
+		return values[intn(len(values))], nil
+	}
+}
@@ -0,0 +1,112 @@
+package iter
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRepeatN(t *testing.T) {
+	for _, n := range []int{3, 0, -1} {
+		got, err := ToSlice(RepeatN("x", n))
+		if err != nil || len(got) != max(n, 0) {
+			t.Fatalf("n %d: got %v, %v, want %d elements", n, got, err, max(n, 0))
+		}
+	}
+}
+
+func TestRepeatNSafe(t *testing.T) {
+	const n = 10000
+	it := RepeatNSafe(1, n)
+	var (
+		total atomic.Int64
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := it()
+				if err != nil {
+					return
+				}
+				total.Add(int64(v))
+			}
+		}()
+	}
+	wg.Wait()
+	if total.Load() != n {
+		t.Fatalf("got %d values in total, want %d", total.Load(), n)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the end, want ErrStopIt", err)
+	}
+}
+
+func TestRange(t *testing.T) {
+	for _, tc := range []struct {
+		start, stop, step int
+		want              []int
+	}{
+		{0, 5, 2, []int{0, 2, 4}},
+		{5, 0, -2, []int{5, 3, 1}},
+		{0, 5, -1, nil},
+		{3, 3, 1, nil},
+		{math.MaxInt - 2, math.MaxInt, 1, []int{math.MaxInt - 2, math.MaxInt - 1}},
+		{math.MinInt + 1, math.MinInt, -5, []int{math.MinInt + 1}},
+		{math.MinInt, math.MaxInt, math.MaxInt, []int{math.MinInt, -1, math.MaxInt - 1}},
+		{math.MaxInt, math.MinInt, math.MinInt, []int{math.MaxInt, -1}},
+	} {
+		got, err := ToSlice(Range(tc.start, tc.stop, tc.step))
+		if err != nil || !slices.Equal(got, tc.want) {
+			t.Errorf("Range(%d, %d, %d): got %v, %v, want %v, nil", tc.start, tc.stop, tc.step, got, err, tc.want)
+		}
+	}
+}
+
+func TestRangeInvalid(t *testing.T) {
+	for name, it := range map[string]Iterator[int]{
+		"Range":              Range(0, 5, 0),
+		"RangeSafe":          RangeSafe(0, 5, 0),
+		"Range too long":     Range(math.MinInt, math.MaxInt, 1),
+		"RangeSafe too long": RangeSafe(math.MaxInt, math.MinInt, -1),
+	} {
+		if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: got %v, want ErrInvalidArgument", name, err)
+		}
+	}
+}
+
+func TestRandomIntsWideInterval(t *testing.T) {
+	for _, tc := range [][2]int{{math.MinInt, math.MaxInt}, {-1, math.MaxInt}, {math.MinInt, 1}, {-3, 4}} {
+		it := RandomInts(rand.New(rand.NewSource(1)), tc[0], tc[1])
+		for i := 0; i < 1000; i++ {
+			v, err := it()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v < tc[0] || v >= tc[1] {
+				t.Fatalf("interval %v: got %d", tc, v)
+			}
+		}
+	}
+	// Both halves of the widest interval are drawn.
+	negative := 0
+	it := RandomInts(rand.New(rand.NewSource(1)), math.MinInt, math.MaxInt)
+	for i := 0; i < 1000; i++ {
+		if v, _ := it(); v < 0 {
+			negative++
+		}
+	}
+	if negative < 400 || negative > 600 {
+		t.Fatalf("%d of 1000 values are negative, want about 500", negative)
+	}
+	if _, err := RandomInts(nil, 5, 5)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v for an empty interval, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/zkksch/iter
+
+go 1.24
@@ -0,0 +1,59 @@
+package iter
+
+import (
+	"context"
+	"sync"
+)
+
+// holding is a stage keeping elements pulled from its source
+// but not yet yielded, like Buffer. DrainOnCancel hands off only
+// the elements held by such stages.
+type holding struct {
+	// held returns the number of the elements, it's called after settled is closed.
+	held func() int
+	// settled is closed once the stage no longer pulls its source.
+	settled <-chan struct{}
+}
+
+var holdings = struct {
+	sync.Mutex
+	byCtx map[context.Context][]*holding
+}{byCtx: make(map[context.Context][]*holding)}
+
+// hold registers the stage working under the context.
+// Stages are forgotten when the context is done, the ones registered earlier
+// are still known to DrainOnCancel calls started before that.
+// It returns a function to forget the stage earlier.
+func hold(ctx context.Context, h *holding) (release func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	holdings.Lock()
+	defer holdings.Unlock()
+	if _, ok := holdings.byCtx[ctx]; !ok {
+		context.AfterFunc(ctx, func() {
+			holdings.Lock()
+			defer holdings.Unlock()
+			delete(holdings.byCtx, ctx)
+		})
+	}
+	holdings.byCtx[ctx] = append(holdings.byCtx[ctx], h)
+	return func() {
+		holdings.Lock()
+		defer holdings.Unlock()
+		list := holdings.byCtx[ctx]
+		for i := range list {
+			if list[i] == h {
+				holdings.byCtx[ctx] = append(list[:i:i], list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// heldBy returns the stages registered under the context.
+func heldBy(ctx context.Context) []*holding {
+	holdings.Lock()
+	defer holdings.Unlock()
+	return append([]*holding(nil), holdings.byCtx[ctx]...)
+}
@@ -0,0 +1,266 @@
+package iter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrRecordTooLarge is returned by FromLengthPrefixed
+// when a record is longer than the limit.
+var ErrRecordTooLarge = errors.New("record is too large")
+
+type readerConfig struct {
+	copy bool
+}
+
+// ReaderOption configures iterators reading from an io.Reader.
+type ReaderOption func(*readerConfig)
+
+// WithCopy makes the iterator yield slices owned by the caller
+// instead of slices reusing the internal buffer.
+func WithCopy() ReaderOption {
+	return func(c *readerConfig) {
+		c.copy = true
+	}
+}
+
+// FromReaderBytes returns an iterator of lines read from r
+// without the trailing end of line marker ("\n" or "\r\n").
+//
+// By default the yielded slice refers to the internal buffer and is valid
+// only until the next call of the iterator, use WithCopy to get owned slices.
+// Lines longer than the buffer are supported.
+// io.EOF is converted to ErrStopIt, any other read error is returned as is.
+func FromReaderBytes(r io.Reader, opts ...ReaderOption) Iterator[[]byte] {
+	var config readerConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	reader := bufio.NewReader(r)
+	var (
+		long []byte
+		done bool
+	)
+	return func() ([]byte, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			long = append(long[:0], line...)
+			for errors.Is(err, bufio.ErrBufferFull) {
+				line, err = reader.ReadSlice('\n')
+				long = append(long, line...)
+			}
+			line = long
+		}
+		if errors.Is(err, io.EOF) {
+			done = true
+			if len(line) == 0 {
+				return nil, ErrStopIt
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSuffix(line, []byte{'\n'})
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		if config.copy {
+			line = bytes.Clone(line)
+		}
+		return line, nil
+	}
+}
+
+// Lines returns an iterator of lines read from r as strings,
+// it is FromReaderBytes converting every line to a string.
+func Lines(r io.Reader) Iterator[string] {
+	lines := FromReaderBytes(r)
+	return func() (string, error) {
+		line, err := lines()
+		if err != nil {
+			return "", err
+		}
+		return string(line), nil
+	}
+}
+
+// ReadChunks returns an iterator of chunks of size bytes read from r,
+// only the last chunk can be shorter. Every yielded slice is owned by the caller.
+//
+// io.EOF is converted to ErrStopIt, any other read error is returned as is.
+// Errors stop the iterator. Non-positive size results
+// in an iterator returning ErrInvalidArgument.
+func ReadChunks(r io.Reader, size int) Iterator[[]byte] {
+	if size <= 0 {
+		return Err[[]byte](fmt.Errorf("%w: non-positive chunk size %d", ErrInvalidArgument, size))
+	}
+	done := false
+	return func() ([]byte, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		chunk := make([]byte, size)
+		n, err := io.ReadFull(r, chunk)
+		switch {
+		case errors.Is(err, io.EOF):
+			done = true
+			return nil, ErrStopIt
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			done = true
+		case err != nil:
+			done = true
+			return nil, err
+		}
+		return chunk[:n:n], nil
+	}
+}
+
+// FromScanner returns an iterator of values made by parse from tokens of s.
+// The token passed to parse is valid only during the call.
+//
+// A parse error is returned and doesn't stop the iterator.
+// The end of input stops the iterator, an error of s is returned and stops it.
+func FromScanner[T any](s *bufio.Scanner, parse func([]byte) (T, error)) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !s.Scan() {
+			done = true
+			if err := s.Err(); err != nil {
+				return empty, err
+			}
+			return empty, ErrStopIt
+		}
+		return parse(s.Bytes())
+	}
+}
+
+// Tokens returns an iterator of tokens of r produced by split, like bufio.ScanWords.
+func Tokens(r io.Reader, split bufio.SplitFunc) Iterator[string] {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(split)
+	return FromScanner(scanner, func(token []byte) (string, error) {
+		return string(token), nil
+	})
+}
+
+// FromLengthPrefixed returns an iterator of records read from r,
+// every record is a uvarint length followed by that many bytes of payload.
+//
+// A record longer than maxRecord results in ErrRecordTooLarge.
+// The end of r at a record boundary stops the iterator,
+// the end of r inside a record results in io.ErrUnexpectedEOF.
+// Errors stop the iterator. Every yielded slice is owned by the caller.
+// Negative maxRecord results in an iterator returning ErrInvalidArgument.
+func FromLengthPrefixed(r io.Reader, maxRecord int) Iterator[[]byte] {
+	if maxRecord < 0 {
+		return Err[[]byte](fmt.Errorf("%w: negative record limit %d", ErrInvalidArgument, maxRecord))
+	}
+	reader, ok := r.(interface {
+		io.Reader
+		io.ByteReader
+	})
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+	done := false
+	return func() ([]byte, error) {
+		if done {
+			return nil, ErrStopIt
+		}
+		size, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			done = true
+			return nil, ErrStopIt
+		}
+		if err != nil {
+			done = true
+			return nil, err
+		}
+		if size > uint64(maxRecord) {
+			done = true
+			return nil, fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrRecordTooLarge, size, maxRecord)
+		}
+		record := make([]byte, size)
+		if _, err := io.ReadFull(reader, record); err != nil {
+			done = true
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return record, nil
+	}
+}
+
+// WriteLengthPrefixed consumes the iterator writing every element to w
+// as a uvarint length followed by the element, the format read by FromLengthPrefixed.
+// It returns the first error of the iterator or w.
+func WriteLengthPrefixed(w io.Writer, it Iterator[[]byte]) error {
+	var prefix [binary.MaxVarintLen64]byte
+	for {
+		record, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		n := binary.PutUvarint(prefix[:], uint64(len(record)))
+		if _, err := w.Write(prefix[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+}
+
+type reader[T []byte | string] struct {
+	it      Iterator[T]
+	current T
+	err     error
+}
+
+func (r *reader[T]) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		next, err := r.it()
+		if errors.Is(err, ErrStopIt) {
+			r.err = io.EOF
+			continue
+		}
+		if err != nil {
+			r.err = fmt.Errorf("iterator: %w", err)
+			continue
+		}
+		r.current = next
+	}
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// NewReader returns a reader of bytes of the elements of the iterator.
+// The reader returns io.EOF when the iterator stops,
+// other errors of the iterator are wrapped and returned after all the bytes before them.
+func NewReader(it Iterator[[]byte]) io.Reader {
+	return &reader[[]byte]{it: it}
+}
+
+// NewStringReader works as NewReader for an iterator of strings.
+func NewStringReader(it Iterator[string]) io.Reader {
+	return &reader[string]{it: it}
+}
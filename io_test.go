@@ -0,0 +1,371 @@
+package iter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFromReaderBytes(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+	input := "first\r\n" + long + "\n\nlast"
+	for _, opts := range [][]ReaderOption{nil, {WithCopy()}} {
+		var got []string
+		it := FromReaderBytes(strings.NewReader(input), opts...)
+		for {
+			line, err := it()
+			if err != nil {
+				if !errors.Is(err, ErrStopIt) {
+					t.Fatal(err)
+				}
+				break
+			}
+			got = append(got, string(line))
+		}
+		want := []string{"first", long, "", "last"}
+		if len(got) != len(want) {
+			t.Fatalf("got %d lines, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("line %d: got %d bytes %.20q, want %d bytes %.20q", i, len(got[i]), got[i], len(want[i]), want[i])
+			}
+		}
+	}
+}
+
+// numberedLines returns n lines "line-0000000" separated by "\n".
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line-%07d\n", i)
+	}
+	return b.String()
+}
+
+func TestFromReaderBytesAliasing(t *testing.T) {
+	// The lines take several buffer refills, so the buffer is reused.
+	input := numberedLines(2000)
+	var shared, owned [][]byte
+	it := FromReaderBytes(strings.NewReader(input))
+	copied := FromReaderBytes(strings.NewReader(input), WithCopy())
+	for {
+		line, err := it()
+		if err != nil {
+			break
+		}
+		shared = append(shared, line)
+		line, _ = copied()
+		owned = append(owned, line)
+	}
+	if len(shared) != 2000 || len(owned) != 2000 {
+		t.Fatalf("got %d and %d lines, want 2000", len(shared), len(owned))
+	}
+	overwritten := 0
+	for i := range owned {
+		want := fmt.Sprintf("line-%07d", i)
+		if string(owned[i]) != want {
+			t.Fatalf("copied line %d: got %q, want %q", i, owned[i], want)
+		}
+		if string(shared[i]) != want {
+			overwritten++
+		}
+	}
+	if overwritten == 0 {
+		t.Fatal("lines yielded without WithCopy weren't overwritten by later reads")
+	}
+}
+
+func countMatching[T any](it Iterator[T], pred func(T) bool) (int, error) {
+	n := 0
+	for {
+		v, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if pred(v) {
+			n++
+		}
+	}
+}
+
+var benchmarkLines = sync.OnceValue(func() string {
+	var b strings.Builder
+	for i := 0; i < 1000000; i++ {
+		if i%100 == 0 {
+			fmt.Fprintf(&b, "%07d error: something failed\n", i)
+		} else {
+			fmt.Fprintf(&b, "%07d info: everything is fine\n", i)
+		}
+	}
+	return b.String()
+})
+
+func BenchmarkFromReaderBytes(b *testing.B) {
+	input := benchmarkLines()
+	pattern := []byte("error")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, err := countMatching(FromReaderBytes(strings.NewReader(input)), func(line []byte) bool {
+			return bytes.Contains(line, pattern)
+		})
+		if err != nil || n != 10000 {
+			b.Fatalf("got %d, %v, want 10000, nil", n, err)
+		}
+	}
+}
+
+func BenchmarkLines(b *testing.B) {
+	input := benchmarkLines()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n, err := countMatching(Lines(strings.NewReader(input)), func(line string) bool {
+			return strings.Contains(line, "error")
+		})
+		if err != nil || n != 10000 {
+			b.Fatalf("got %d, %v, want 10000, nil", n, err)
+		}
+	}
+}
+
+func TestReadChunks(t *testing.T) {
+	// One byte per read, every chunk still takes several reads.
+	chunks, err := ToSlice(ReadChunks(iotest.OneByteReader(strings.NewReader("abcdefghij")), 4))
+	if err != nil || len(chunks) != 3 {
+		t.Fatalf("got %q, %v, want 3 chunks", chunks, err)
+	}
+	for i, want := range []string{"abcd", "efgh", "ij"} {
+		if string(chunks[i]) != want {
+			t.Fatalf("chunk %d: got %q, want %q", i, chunks[i], want)
+		}
+	}
+	// The chunks don't share memory, appending to one doesn't overwrite the next.
+	chunks[0][0] = 'X'
+	chunks[0] = append(chunks[0], 'Y')
+	if string(chunks[1]) != "efgh" || string(chunks[2]) != "ij" {
+		t.Fatalf("chunks changed by writes to another one: %q", chunks)
+	}
+	if chunks, err = ToSlice(ReadChunks(strings.NewReader("abcdefgh"), 4)); err != nil || len(chunks) != 2 {
+		t.Fatalf("got %q, %v, want 2 full chunks and no empty one", chunks, err)
+	}
+}
+
+func TestReadChunksErrors(t *testing.T) {
+	failure := errors.New("failure")
+	it := ReadChunks(io.MultiReader(strings.NewReader("abcde"), iotest.ErrReader(failure)), 4)
+	if chunk, err := it(); err != nil || string(chunk) != "abcd" {
+		t.Fatalf("got %q, %v, want abcd, nil", chunk, err)
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+	if _, err := ReadChunks(strings.NewReader("abc"), 0)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestFromScanner(t *testing.T) {
+	input := numberedLines(500)
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	// A small buffer is refilled many times, parsed values must not alias it.
+	scanner.Buffer(make([]byte, 32), 32)
+	got, err := ToSlice(FromScanner(scanner, func(token []byte) (string, error) {
+		return string(token), nil
+	}))
+	if err != nil || len(got) != 500 {
+		t.Fatalf("got %d values, %v, want 500, nil", len(got), err)
+	}
+	for i, v := range got {
+		if want := fmt.Sprintf("line-%07d", i); v != want {
+			t.Fatalf("value %d: got %q, want %q", i, v, want)
+		}
+	}
+}
+
+func TestFromScannerErrors(t *testing.T) {
+	it := FromScanner(bufio.NewScanner(strings.NewReader("1\nx\n3\n")), func(token []byte) (int, error) {
+		return strconv.Atoi(string(token))
+	})
+	if v, err := it(); err != nil || v != 1 {
+		t.Fatalf("got %v, %v, want 1, nil", v, err)
+	}
+	// A parse error doesn't stop the iterator.
+	if _, err := it(); !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("got %v, want strconv.ErrSyntax", err)
+	}
+	if v, err := it(); err != nil || v != 3 {
+		t.Fatalf("got %v, %v, want 3, nil", v, err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(strings.Repeat("x", 100)))
+	scanner.Buffer(nil, 10)
+	tooLong := FromScanner(scanner, func(token []byte) ([]byte, error) { return token, nil })
+	if _, err := tooLong(); !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("got %v, want bufio.ErrTooLong", err)
+	}
+	if _, err := tooLong(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	got, err := ToSlice(Tokens(strings.NewReader("  the quick\n\tbrown  fox "), bufio.ScanWords))
+	if want := []string{"the", "quick", "brown", "fox"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, want)
+	}
+}
+
+func TestLengthPrefixedRoundTrip(t *testing.T) {
+	records := [][]byte{[]byte("a"), {}, bytes.Repeat([]byte("x"), 300), []byte("last")}
+	var buf bytes.Buffer
+	if err := WriteLengthPrefixed(&buf, FromSlice(records)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ToSlice(FromLengthPrefixed(&buf, 1000))
+	if err != nil || !slices.EqualFunc(got, records, bytes.Equal) {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, records)
+	}
+}
+
+func TestFromLengthPrefixedZeroLength(t *testing.T) {
+	it := FromLengthPrefixed(bytes.NewReader([]byte{0, 0}), 10)
+	for i := 0; i < 2; i++ {
+		if record, err := it(); err != nil || len(record) != 0 {
+			t.Fatalf("record %d: got %q, %v, want empty, nil", i, record, err)
+		}
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+}
+
+func TestFromLengthPrefixedBoundaryEOF(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{"empty input", nil, ErrStopIt},
+		{"truncated payload", []byte{3, 'a', 'b'}, io.ErrUnexpectedEOF},
+		{"truncated length", []byte{1, 'a', 0x80}, io.ErrUnexpectedEOF},
+	} {
+		it := FromLengthPrefixed(bytes.NewReader(tc.input), 10)
+		var err error
+		for err == nil {
+			_, err = it()
+		}
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+		if _, err := it(); !errors.Is(err, ErrStopIt) {
+			t.Errorf("%s: got %v after the error, want ErrStopIt", tc.name, err)
+		}
+	}
+}
+
+func TestFromLengthPrefixedTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteLengthPrefixed(&buf, FromSlice([][]byte{[]byte("ok"), []byte("too long")})); err != nil {
+		t.Fatal(err)
+	}
+	it := FromLengthPrefixed(&buf, 4)
+	if record, err := it(); err != nil || string(record) != "ok" {
+		t.Fatalf("got %q, %v, want ok, nil", record, err)
+	}
+	if _, err := it(); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("got %v, want ErrRecordTooLarge", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestFromLengthPrefixedNegativeLimit(t *testing.T) {
+	// The length prefix of 1<<40 bytes must not be allocated.
+	var buf bytes.Buffer
+	buf.Write(binary.AppendUvarint(nil, 1<<40))
+	if _, err := FromLengthPrefixed(&buf, -1)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestNewReaderReadAll(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var chunks [][]byte
+	var want []byte
+	for i := 0; i < 200; i++ {
+		chunk := make([]byte, rng.Intn(20))
+		rng.Read(chunk)
+		chunks = append(chunks, chunk)
+		want = append(want, chunk...)
+	}
+	got, err := io.ReadAll(NewReader(FromSlice(chunks)))
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("got %d bytes, %v, want %d bytes, nil", len(got), err, len(want))
+	}
+}
+
+func TestNewReaderSmallBuffer(t *testing.T) {
+	r := NewStringReader(FromSlice([]string{"hello", "", " ", "world"}))
+	var got []byte
+	buf := make([]byte, 3)
+	for {
+		n, err := r.Read(buf)
+		if n > 3 {
+			t.Fatalf("read %d bytes into a buffer of 3", n)
+		}
+		got = append(got, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	failure := errors.New("failure")
+	elements := []string{"ab", "cd"}
+	it := func() (string, error) {
+		if len(elements) == 0 {
+			return "", failure
+		}
+		s := elements[0]
+		elements = elements[1:]
+		return s, nil
+	}
+	got, err := io.ReadAll(NewStringReader(it))
+	if string(got) != "abcd" || !errors.Is(err, failure) {
+		t.Fatalf("got %q, %v, want abcd, %v", got, err, failure)
+	}
+}
+
+func TestNewReaderDecoder(t *testing.T) {
+	var values []int
+	dec := json.NewDecoder(NewStringReader(FromSlice([]string{"[1,", "2", "2,3", "]"})))
+	if err := dec.Decode(&values); err != nil || !slices.Equal(values, []int{1, 22, 3}) {
+		t.Fatalf("got %v, %v, want [1 22 3], nil", values, err)
+	}
+}
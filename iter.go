@@ -0,0 +1,44 @@
+// Package iter provides implementations of popular iteration tools.
+//
+// An iterator is a function returning the next element of a sequence.
+// When the sequence is exhausted the iterator returns ErrStopIt, any
+// other error is a failure of the iterator or of one of its sources.
+package iter
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrStopIt is returned by an iterator when there are no more elements.
+var ErrStopIt = errors.New("stop iteration")
+
+// Iterator returns the next element of a sequence on every call.
+// It returns ErrStopIt when the sequence is exhausted.
+type Iterator[T any] func() (T, error)
+
+// ErrInvalidArgument is returned by iterators constructed with invalid arguments.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Pair is a pair of values of possibly different types.
+type Pair[T, K any] struct {
+	Left  T
+	Right K
+}
+
+// safe returns an iterator serializing calls of it with a mutex.
+func safe[T any](it Iterator[T]) Iterator[T] {
+	var mu sync.Mutex
+	return func() (T, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return it()
+	}
+}
+
+// Triple is a triple of values of possibly different types.
+type Triple[A, B, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
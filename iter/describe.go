@@ -0,0 +1,115 @@
+package iter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// StageInfo describes a stage of a pipeline.
+type StageInfo struct {
+	Name   string
+	Params []string
+}
+
+func (s StageInfo) String() string {
+	return s.Name + "(" + strings.Join(s.Params, ", ") + ")"
+}
+
+// Describer is implemented by iterators describing the pipeline they are built of.
+// All the iterators of the package implement it.
+type Describer interface {
+	Describe() string
+}
+
+// stager is implemented by the iterators of the package,
+// it returns the stage of the iterator and the iterators it pulls from.
+type stager interface {
+	stage() (info StageInfo, upstream []any)
+}
+
+// Explain returns the stages of the pipeline of the iterator, sources first.
+// An iterator which isn't built by the package is described as one stage
+// named after its type, use Described to give it a name.
+func Explain[T any](it Iterator[T]) []StageInfo {
+	return explain(it, nil)
+}
+
+func explain(it any, stages []StageInfo) []StageInfo {
+	s, ok := it.(stager)
+	if !ok {
+		return append(stages, StageInfo{Name: fmt.Sprintf("%T", it)})
+	}
+	info, upstream := s.stage()
+	for _, source := range upstream {
+		stages = explain(source, stages)
+	}
+	return append(stages, info)
+}
+
+// describe joins the stages of the pipeline of the iterator.
+func describe(it any) string {
+	stages := explain(it, nil)
+	parts := make([]string, len(stages))
+	for i, s := range stages {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, " | ")
+}
+
+// stage returns the stage info with params formatted for a description,
+// functions are formatted as <func> and slices by their length.
+func stage(name string, params ...any) StageInfo {
+	info := StageInfo{Name: name, Params: make([]string, len(params))}
+	for i, p := range params {
+		switch v := reflect.ValueOf(p); v.Kind() {
+		case reflect.Func:
+			info.Params[i] = "<func>"
+		case reflect.Slice:
+			info.Params[i] = fmt.Sprintf("<%d elements>", v.Len())
+		default:
+			info.Params[i] = fmt.Sprint(p)
+		}
+	}
+	return info
+}
+
+type described[T any] struct {
+	Iterator[T]
+	info StageInfo
+}
+
+func (it *described[T]) stage() (StageInfo, []any) {
+	return it.info, nil
+}
+
+func (it *described[T]) Describe() string {
+	return describe(it)
+}
+
+// Described returns an iterator passing through elements of the source
+// which is described as one stage with the name and params,
+// hiding the description of the source.
+func Described[T any](name string, source Iterator[T], params ...any) Iterator[T] {
+	return &described[T]{Iterator: source, info: stage(name, params...)}
+}
+
+type fromFunc[T any] func() (T, error)
+
+func (fn fromFunc[T]) Next() (T, error) {
+	return fn()
+}
+
+func (fn fromFunc[T]) stage() (StageInfo, []any) {
+	return stage("FromFunc", fn), nil
+}
+
+func (fn fromFunc[T]) Describe() string {
+	return describe(fn)
+}
+
+// FromFunc returns an iterator calling fn for every element,
+// it adapts iterators of the root package and any other next functions.
+func FromFunc[T any](fn func() (T, error)) Iterator[T] {
+	return fromFunc[T](fn)
+}
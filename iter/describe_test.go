@@ -0,0 +1,63 @@
+package iter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	it := Limit(TakeWhile(FromSlice([]int{1, 2, 3, 4}), func(v int) bool { return v < 4 }), 100)
+	progress, _ := WithProgress(it)
+	want := []StageInfo{
+		{Name: "FromSlice", Params: []string{"<4 elements>"}},
+		{Name: "TakeWhile", Params: []string{"<func>"}},
+		{Name: "Limit", Params: []string{"100"}},
+		{Name: "WithProgress", Params: []string{}},
+	}
+	got := Explain(progress)
+	if !slices.EqualFunc(got, want, func(a, b StageInfo) bool {
+		return a.Name == b.Name && slices.Equal(a.Params, b.Params)
+	}) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if d, ok := progress.(Describer); !ok || d.Describe() != "FromSlice(<4 elements>) | TakeWhile(<func>) | Limit(100) | WithProgress()" {
+		t.Fatalf("got description %q", d.Describe())
+	}
+	// Explain doesn't run the pipeline.
+	if values, err := collect(progress); err != nil || !slices.Equal(values, []int{1, 2, 3}) {
+		t.Fatalf("got %v, %v, want [1 2 3], nil", values, err)
+	}
+}
+
+func TestExplainSources(t *testing.T) {
+	for _, tc := range []struct {
+		it   Iterator[int]
+		want string
+	}{
+		{Empty[int](), "Empty()"},
+		{Err[int](errors.New("failure")), "Err(failure)"},
+		{GenerateN(5, func(i int) (int, error) { return i, nil }), "GenerateN(5, <func>)"},
+		{GenerateIndexed(func(i int) (int, error) { return i, nil }), "GenerateIndexed(<func>)"},
+	} {
+		if got := tc.it.(Describer).Describe(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestExplainForeign(t *testing.T) {
+	// A foreign iterator is one stage, Described gives it a name and hides its sources.
+	foreign := FromFunc(func() (int, error) { return 0, ErrStopIt })
+	if got := Limit(foreign, 3).(Describer).Describe(); got != "FromFunc(<func>) | Limit(3)" {
+		t.Fatalf("got %q", got)
+	}
+	named := Described("Users", Limit(foreign, 3), "db")
+	if got := Limit(named, 1).(Describer).Describe(); got != "Users(db) | Limit(1)" {
+		t.Fatalf("got %q", got)
+	}
+	type custom struct{ Iterator[int] }
+	if got := Explain[int](custom{Empty[int]()}); len(got) != 1 || got[0].Name != "iter.custom" {
+		t.Fatalf("got %v, want one stage named after the type", got)
+	}
+}
@@ -0,0 +1,56 @@
+package iter
+
+import "errors"
+
+type indexed[T any] struct {
+	fn    func(i int) (T, error)
+	i     int
+	limit int
+	done  bool
+}
+
+func (g *indexed[T]) Next() (T, error) {
+	var empty T
+	if g.done || (g.limit >= 0 && g.i >= g.limit) {
+		return empty, ErrStopIt
+	}
+	value, err := g.fn(g.i)
+	if err != nil {
+		g.done = true
+		if errors.Is(err, ErrStopIt) {
+			return empty, ErrStopIt
+		}
+		return empty, err
+	}
+	g.i++
+	return value, nil
+}
+
+func (g *indexed[T]) stage() (StageInfo, []any) {
+	if g.limit < 0 {
+		return stage("GenerateIndexed", g.fn), nil
+	}
+	return stage("GenerateN", g.limit, g.fn), nil
+}
+
+func (g *indexed[T]) Describe() string {
+	return describe(g)
+}
+
+// GenerateN returns an iterator of n elements produced by fn called
+// with the index of the element.
+//
+// The iterator stops earlier if fn returns ErrStopIt.
+// Any other error from fn is returned and stops the iterator.
+func GenerateN[T any](n int, fn func(i int) (T, error)) Iterator[T] {
+	if n < 0 {
+		n = 0
+	}
+	return &indexed[T]{fn: fn, limit: n}
+}
+
+// GenerateIndexed returns an unbounded version of GenerateN,
+// it stops only when fn returns an error.
+func GenerateIndexed[T any](fn func(i int) (T, error)) Iterator[T] {
+	return &indexed[T]{fn: fn, limit: -1}
+}
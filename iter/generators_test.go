@@ -0,0 +1,82 @@
+package iter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// collect returns the elements of the iterator until the first error,
+// ErrStopIt isn't returned.
+func collect[T any](it Iterator[T]) ([]T, error) {
+	var values []T
+	for {
+		v, err := it.Next()
+		if errors.Is(err, ErrStopIt) {
+			return values, nil
+		}
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+}
+
+func TestGenerateN(t *testing.T) {
+	got, err := collect(GenerateN(4, func(i int) (int, error) { return i * i, nil }))
+	if want := []int{0, 1, 4, 9}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestGenerateNZero(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		called := false
+		got, err := collect(GenerateN(n, func(i int) (int, error) {
+			called = true
+			return i, nil
+		}))
+		if err != nil || len(got) != 0 || called {
+			t.Fatalf("n %d: got %v, %v, fn called %v, want [], nil and no calls", n, got, err, called)
+		}
+	}
+}
+
+func TestGenerateNEarlyStop(t *testing.T) {
+	it := GenerateN(10, func(i int) (int, error) {
+		if i == 3 {
+			return 0, ErrStopIt
+		}
+		return i, nil
+	})
+	got, err := collect(it)
+	if want := []int{0, 1, 2}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestGenerateNError(t *testing.T) {
+	failure := errors.New("failure")
+	calls := 0
+	it := GenerateN(10, func(i int) (int, error) {
+		calls++
+		if i == 2 {
+			return 0, failure
+		}
+		return i, nil
+	})
+	got, err := collect(it)
+	if want := []int{0, 1}; !errors.Is(err, failure) || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, %v", got, err, want, failure)
+	}
+	if _, err := it.Next(); !errors.Is(err, ErrStopIt) || calls != 3 {
+		t.Fatalf("got %v after %d calls, want ErrStopIt and no more calls", err, calls)
+	}
+}
+
+func TestGenerateIndexed(t *testing.T) {
+	got, err := collect(Limit(GenerateIndexed(func(i int) (int, error) { return i + 100, nil }), 3))
+	if want := []int{100, 101, 102}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
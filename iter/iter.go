@@ -0,0 +1,33 @@
+// Package iter provides interface-based implementations of popular iteration tools.
+//
+// It mirrors the function-based root package, iterators here are values
+// implementing the Iterator interface.
+package iter
+
+import root "github.com/zkksch/iter"
+
+// ErrStopIt is returned by an iterator when there are no more elements.
+// It is the same error as the one of the root package.
+var ErrStopIt = root.ErrStopIt
+
+// Iterator returns the next element of a sequence on every Next call.
+// Next returns ErrStopIt when the sequence is exhausted.
+type Iterator[T any] interface {
+	Next() (T, error)
+}
+
+// SizeHinter is implemented by iterators knowing how many elements they have left.
+type SizeHinter interface {
+	// SizeHint returns the number of elements left, it's an upper bound
+	// if the exact number depends on a source of unknown size.
+	// ok is false if the number is unknown or the iterator is unbounded.
+	SizeHint() (n int, ok bool)
+}
+
+// SizeHint returns the size hint of the iterator if it implements SizeHinter.
+func SizeHint[T any](it Iterator[T]) (n int, ok bool) {
+	if hinter, isHinter := it.(SizeHinter); isHinter {
+		return hinter.SizeHint()
+	}
+	return 0, false
+}
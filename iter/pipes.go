@@ -0,0 +1,120 @@
+package iter
+
+import "sync/atomic"
+
+type takeWhile[T any] struct {
+	source Iterator[T]
+	pred   func(T) bool
+	done   bool
+}
+
+func (it *takeWhile[T]) Next() (T, error) {
+	var empty T
+	if it.done {
+		return empty, ErrStopIt
+	}
+	value, err := it.source.Next()
+	if err != nil {
+		return empty, err
+	}
+	if !it.pred(value) {
+		it.done = true
+		return empty, ErrStopIt
+	}
+	return value, nil
+}
+
+func (it *takeWhile[T]) stage() (StageInfo, []any) {
+	return stage("TakeWhile", it.pred), []any{it.source}
+}
+
+func (it *takeWhile[T]) Describe() string {
+	return describe(it)
+}
+
+// TakeWhile returns an iterator yielding elements of the source
+// while pred returns true for them.
+// The iterator stops on the first element failing pred
+// and doesn't call the source anymore.
+func TakeWhile[T any](source Iterator[T], pred func(T) bool) Iterator[T] {
+	return &takeWhile[T]{source: source, pred: pred}
+}
+
+type limit[T any] struct {
+	source Iterator[T]
+	n      int
+	left   int
+}
+
+func (it *limit[T]) Next() (T, error) {
+	if it.left <= 0 {
+		var empty T
+		return empty, ErrStopIt
+	}
+	value, err := it.source.Next()
+	if err == nil {
+		it.left--
+	}
+	return value, err
+}
+
+func (it *limit[T]) SizeHint() (int, bool) {
+	n, ok := SizeHint(it.source)
+	if !ok || n > it.left {
+		return max(it.left, 0), true
+	}
+	return n, true
+}
+
+func (it *limit[T]) stage() (StageInfo, []any) {
+	return stage("Limit", it.n), []any{it.source}
+}
+
+func (it *limit[T]) Describe() string {
+	return describe(it)
+}
+
+// Limit returns an iterator yielding at most n elements of the source.
+func Limit[T any](source Iterator[T], n int) Iterator[T] {
+	return &limit[T]{source: source, n: n, left: n}
+}
+
+type progress[T any] struct {
+	source Iterator[T]
+	done   atomic.Int64
+}
+
+func (it *progress[T]) Next() (T, error) {
+	value, err := it.source.Next()
+	if err == nil {
+		it.done.Add(1)
+	}
+	return value, err
+}
+
+func (it *progress[T]) SizeHint() (int, bool) {
+	return SizeHint(it.source)
+}
+
+func (it *progress[T]) stage() (StageInfo, []any) {
+	return stage("WithProgress"), []any{it.source}
+}
+
+func (it *progress[T]) Describe() string {
+	return describe(it)
+}
+
+// WithProgress returns an iterator passing through elements of the source
+// and a function reporting the progress of the iteration.
+//
+// The progress function returns the number of elements yielded so far
+// and the total from the size hint the source had when WithProgress was called,
+// ok is false if the source has no size hint.
+// The progress function is safe to call concurrently with the iteration.
+func WithProgress[T any](source Iterator[T]) (Iterator[T], func() (done int, total int, ok bool)) {
+	total, ok := SizeHint(source)
+	it := &progress[T]{source: source}
+	return it, func() (int, int, bool) {
+		return int(it.done.Load()), total, ok
+	}
+}
@@ -0,0 +1,77 @@
+package iter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWithProgressBounded(t *testing.T) {
+	it, progress := WithProgress(FromSlice([]int{1, 2, 3, 4, 5}))
+	if done, total, ok := progress(); done != 0 || total != 5 || !ok {
+		t.Fatalf("got %d/%d, %v before the iteration, want 0/5, true", done, total, ok)
+	}
+	for i := 1; i <= 2; i++ {
+		if _, err := it.Next(); err != nil {
+			t.Fatal(err)
+		}
+		if done, total, ok := progress(); done != i || total != 5 || !ok {
+			t.Fatalf("got %d/%d, %v, want %d/5, true", done, total, ok, i)
+		}
+	}
+	if _, err := collect(it); err != nil {
+		t.Fatal(err)
+	}
+	if done, total, ok := progress(); done != 5 || total != 5 || !ok {
+		t.Fatalf("got %d/%d, %v after the iteration, want 5/5, true", done, total, ok)
+	}
+}
+
+func TestWithProgressLimit(t *testing.T) {
+	_, progress := WithProgress(Limit(FromSlice(make([]int, 100)), 10))
+	if _, total, ok := progress(); total != 10 || !ok {
+		t.Fatalf("got total %d, %v, want 10, true", total, ok)
+	}
+}
+
+func TestWithProgressUnbounded(t *testing.T) {
+	it, progress := WithProgress(GenerateIndexed(func(i int) (int, error) { return i, nil }))
+	if _, err := collect(Limit(it, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if done, _, ok := progress(); done != 3 || ok {
+		t.Fatalf("got %d, %v, want 3, false", done, ok)
+	}
+}
+
+func TestWithProgressPipes(t *testing.T) {
+	// Pipes between WithProgress and the finalizer see the same elements.
+	it, progress := WithProgress(FromSlice([]int{1, 2, 3, 4, 5, 6}))
+	got, err := collect(Limit(TakeWhile(it, func(v int) bool { return v < 5 }), 10))
+	if want := []int{1, 2, 3, 4}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	// TakeWhile pulled the fifth element to stop on it.
+	if done, total, _ := progress(); done != 5 || total != 6 {
+		t.Fatalf("got %d/%d, want 5/6", done, total)
+	}
+}
+
+func TestWithProgressConcurrentRead(t *testing.T) {
+	it, progress := WithProgress(FromSlice(make([]int, 10000)))
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		last := 0
+		for i := 0; i < 1000; i++ {
+			done, _, _ := progress()
+			if done < last {
+				t.Errorf("progress went back from %d to %d", last, done)
+			}
+			last = done
+		}
+	}()
+	if _, err := collect(it); err != nil {
+		t.Fatal(err)
+	}
+	<-finished
+}
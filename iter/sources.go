@@ -0,0 +1,80 @@
+package iter
+
+import "errors"
+
+type fromSlice[T any] struct {
+	values []T
+	i      int
+}
+
+func (it *fromSlice[T]) Next() (T, error) {
+	if it.i >= len(it.values) {
+		var empty T
+		return empty, ErrStopIt
+	}
+	it.i++
+	return it.values[it.i-1], nil
+}
+
+func (it *fromSlice[T]) SizeHint() (int, bool) {
+	return len(it.values) - it.i, true
+}
+
+func (it *fromSlice[T]) stage() (StageInfo, []any) {
+	return stage("FromSlice", it.values), nil
+}
+
+func (it *fromSlice[T]) Describe() string {
+	return describe(it)
+}
+
+// FromSlice returns an iterator over elements of the slice.
+func FromSlice[T any](values []T) Iterator[T] {
+	return &fromSlice[T]{values: values}
+}
+
+type empty[T any] struct{}
+
+func (empty[T]) Next() (T, error) {
+	var value T
+	return value, ErrStopIt
+}
+
+func (empty[T]) stage() (StageInfo, []any) {
+	return stage("Empty"), nil
+}
+
+func (it empty[T]) Describe() string {
+	return describe(it)
+}
+
+// Empty returns an iterator without elements, it always returns ErrStopIt.
+func Empty[T any]() Iterator[T] {
+	return empty[T]{}
+}
+
+type failure[T any] struct {
+	err error
+}
+
+func (it failure[T]) Next() (T, error) {
+	var value T
+	return value, it.err
+}
+
+func (it failure[T]) stage() (StageInfo, []any) {
+	return stage("Err", it.err), nil
+}
+
+func (it failure[T]) Describe() string {
+	return describe(it)
+}
+
+// Err returns an iterator that always fails with err.
+// Nil err or ErrStopIt makes it an Empty iterator.
+func Err[T any](err error) Iterator[T] {
+	if err == nil || errors.Is(err, ErrStopIt) {
+		return Empty[T]()
+	}
+	return failure[T]{err: err}
+}
@@ -0,0 +1,86 @@
+package iter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FromJSONArray returns an iterator of elements of a JSON array read from r,
+// elements are decoded one by one, so the array is never held in memory.
+//
+// The closing bracket of the array stops the iterator.
+// A value that isn't an array results in an error on the first call,
+// decoding errors are returned as is. Errors stop the iterator.
+func FromJSONArray[T any](r io.Reader) Iterator[T] {
+	decoder := json.NewDecoder(r)
+	started := false
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started = true
+			token, err := decoder.Token()
+			if err == nil && token != json.Delim('[') {
+				err = fmt.Errorf("json: expected an array, got %v", token)
+			}
+			if err != nil {
+				done = true
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				return empty, err
+			}
+		}
+		if !decoder.More() {
+			done = true
+			if _, err := decoder.Token(); err != nil {
+				if errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF
+				}
+				return empty, err
+			}
+			return empty, ErrStopIt
+		}
+		var value T
+		if err := decoder.Decode(&value); err != nil {
+			done = true
+			return empty, err
+		}
+		return value, nil
+	}
+}
+
+// FromJSONLines returns an iterator of values decoded from lines of r
+// in the JSON Lines format, blank lines are skipped.
+//
+// A decoding error is returned with the line number and doesn't stop
+// the iterator, the next call continues with the next line.
+// io.EOF is converted to ErrStopIt, any other read error is returned as is.
+func FromJSONLines[T any](r io.Reader) Iterator[T] {
+	lines := FromReaderBytes(r)
+	number := 0
+	return func() (T, error) {
+		var empty T
+		for {
+			line, err := lines()
+			if err != nil {
+				return empty, err
+			}
+			number++
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var value T
+			if err := json.Unmarshal(line, &value); err != nil {
+				return empty, fmt.Errorf("line %d: %w", number, err)
+			}
+			return value, nil
+		}
+	}
+}
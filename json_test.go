@@ -0,0 +1,42 @@
+package iter
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestFromJSONLines(t *testing.T) {
+	input := "{\"id\": 1, \"name\": \"a\"}\n\n   \r\n{\"id\": 2, \"name\": \"b\"}\r\n{\"id\": 3, \"name\": \"c\"}"
+	got, err := ToSlice(FromJSONLines[record](strings.NewReader(input)))
+	want := []record{{1, "a"}, {2, "b"}, {3, "c"}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestFromJSONLinesDecodeError(t *testing.T) {
+	input := "{\"id\": 1}\n\n{\"id\": \"two\"}\n{broken\n{\"id\": 4}\n"
+	it := FromJSONLines[record](strings.NewReader(input))
+	if v, err := it(); err != nil || v.ID != 1 {
+		t.Fatalf("got %v, %v, want id 1, nil", v, err)
+	}
+	// Blank lines are counted in the line numbers.
+	for _, line := range []string{"line 3:", "line 4:"} {
+		if _, err := it(); err == nil || !strings.HasPrefix(err.Error(), line) {
+			t.Fatalf("got %v, want an error starting with %q", err, line)
+		}
+	}
+	if v, err := it(); err != nil || v.ID != 4 {
+		t.Fatalf("got %v, %v after the errors, want id 4, nil", v, err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+}
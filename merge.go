@@ -0,0 +1,219 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// InterleaveWeighted returns an iterator that cycles through the sources
+// taking weights[i] elements from the source i per round.
+//
+// Exhausted sources are skipped, the iterator stops when all the sources
+// are exhausted. Any other error from a source is returned and stops the iterator.
+// Mismatched lengths of sources and weights or non-positive weights
+// result in an iterator returning ErrInvalidArgument.
+func InterleaveWeighted[T any](sources []Iterator[T], weights []int) Iterator[T] {
+	if len(sources) != len(weights) {
+		return Err[T](fmt.Errorf("%w: %d sources but %d weights", ErrInvalidArgument, len(sources), len(weights)))
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			return Err[T](fmt.Errorf("%w: non-positive weight %d of source %d", ErrInvalidArgument, w, i))
+		}
+	}
+	done := make([]bool, len(sources))
+	active := len(sources)
+	current, taken := 0, 0
+	return func() (T, error) {
+		var empty T
+		for active > 0 {
+			if done[current] || taken >= weights[current] {
+				current = (current + 1) % len(sources)
+				taken = 0
+				continue
+			}
+			value, err := sources[current]()
+			if errors.Is(err, ErrStopIt) {
+				done[current] = true
+				active--
+				continue
+			}
+			if err != nil {
+				active = 0
+				return empty, err
+			}
+			taken++
+			return value, nil
+		}
+		return empty, ErrStopIt
+	}
+}
+
+// PriorityMerge returns an iterator pulling from the non-exhausted source
+// with the highest priority, sources of equal priority are ordered by their index.
+//
+// A source bypassed by maxStarvation consecutive pulls is guaranteed the next pull,
+// the most starved source goes first; non-positive maxStarvation disables the guarantee.
+// Exhausted sources are skipped, the iterator stops when all the sources
+// are exhausted. Any other error from a source is returned and stops the iterator.
+// Mismatched lengths of sources and priorities result in an iterator
+// returning ErrInvalidArgument.
+func PriorityMerge[T any](sources []Iterator[T], priorities []int, maxStarvation int) Iterator[T] {
+	if len(sources) != len(priorities) {
+		return Err[T](fmt.Errorf("%w: %d sources but %d priorities", ErrInvalidArgument, len(sources), len(priorities)))
+	}
+	done := make([]bool, len(sources))
+	bypassed := make([]int, len(sources))
+	active := len(sources)
+	pick := func() int {
+		chosen := -1
+		if maxStarvation > 0 {
+			for i := range sources {
+				if !done[i] && bypassed[i] >= maxStarvation && (chosen < 0 || bypassed[i] > bypassed[chosen]) {
+					chosen = i
+				}
+			}
+			if chosen >= 0 {
+				return chosen
+			}
+		}
+		for i := range sources {
+			if !done[i] && (chosen < 0 || priorities[i] > priorities[chosen]) {
+				chosen = i
+			}
+		}
+		return chosen
+	}
+	return func() (T, error) {
+		var empty T
+		for active > 0 {
+			chosen := pick()
+			value, err := sources[chosen]()
+			if errors.Is(err, ErrStopIt) {
+				done[chosen] = true
+				active--
+				continue
+			}
+			if err != nil {
+				active = 0
+				return empty, err
+			}
+			for i := range bypassed {
+				bypassed[i]++
+			}
+			bypassed[chosen] = 0
+			return value, nil
+		}
+		return empty, ErrStopIt
+	}
+}
+
+// Interleave returns an iterator yielding one element from every source in turn.
+// The iterator stops when any of the sources is exhausted, so every source
+// contributes the same number of elements, except the ones before the exhausted source
+// in the last turn. Any other error from a source is returned and stops the iterator.
+func Interleave[T any](sources ...Iterator[T]) Iterator[T] {
+	current := 0
+	done := len(sources) == 0
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := sources[current]()
+		if err != nil {
+			done = true
+			return empty, err
+		}
+		current = (current + 1) % len(sources)
+		return value, nil
+	}
+}
+
+// RoundRobin returns an iterator yielding one element from every source in turn,
+// exhausted sources are dropped from the rotation and the iterator stops
+// when all the sources are exhausted.
+// Any other error from a source is returned and stops the iterator.
+func RoundRobin[T any](sources ...Iterator[T]) Iterator[T] {
+	weights := make([]int, len(sources))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return InterleaveWeighted(sources, weights)
+}
+
+// RoundRobinSafe works as RoundRobin and is safe for concurrent use.
+func RoundRobinSafe[T any](sources ...Iterator[T]) Iterator[T] {
+	return safe(RoundRobin(sources...))
+}
+
+// Merge returns an iterator of elements of the sources consumed concurrently,
+// every source is pulled in its own goroutine and the elements are yielded
+// in the order they arrive.
+//
+// The iterator stops when all the sources are exhausted. The first error
+// of a source or the context is returned, stops the iterator and the goroutines.
+// A goroutine blocked in its source finishes only after the source returns.
+// If the iterator is abandoned before it stops the context must be cancelled
+// to release the goroutines.
+func Merge[T any](ctx context.Context, sources ...Iterator[T]) Iterator[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	output := make(chan result[T], len(sources))
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for _, source := range sources {
+		go func() {
+			defer wg.Done()
+			for {
+				value, err := source()
+				if errors.Is(err, ErrStopIt) {
+					return
+				}
+				select {
+				case output <- result[T]{value, err}:
+				case <-ctx.Done():
+					return
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		var (
+			r  result[T]
+			ok bool
+		)
+		select {
+		case r, ok = <-output:
+		case <-ctx.Done():
+			r, ok = result[T]{err: ctx.Err()}, true
+		}
+		if !ok {
+			// The goroutines may finish because of the cancellation before it's noticed here.
+			r.err = ErrStopIt
+			if err := ctx.Err(); err != nil {
+				r.err = err
+			}
+		}
+		if r.err != nil {
+			done = true
+			cancel()
+			return empty, r.err
+		}
+		return r.value, nil
+	}
+}
@@ -0,0 +1,241 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestInterleaveWeighted(t *testing.T) {
+	it := InterleaveWeighted([]Iterator[string]{
+		FromSlice([]string{"a1", "a2", "a3", "a4", "a5", "a6"}),
+		FromSlice([]string{"b1", "b2"}),
+	}, []int{2, 1})
+	got, err := ToSlice(it)
+	if want := []string{"a1", "a2", "b1", "a3", "a4", "b2", "a5", "a6"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestInterleaveWeightedDryMidRound(t *testing.T) {
+	it := InterleaveWeighted([]Iterator[string]{
+		FromSlice([]string{"a1", "a2", "a3", "a4", "a5"}),
+		FromSlice([]string{"b1", "b2", "b3"}),
+		FromSlice([]string{"c1"}),
+	}, []int{2, 1, 3})
+	got, err := ToSlice(it)
+	want := []string{"a1", "a2", "b1", "c1", "a3", "a4", "b2", "a5", "b3"}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestInterleaveWeightedErrors(t *testing.T) {
+	failure := errors.New("failure")
+	it := InterleaveWeighted([]Iterator[int]{Range(0, 10, 1), Err[int](failure)}, []int{2, 2})
+	for i := 0; i < 2; i++ {
+		if _, err := it(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+	for _, weights := range [][]int{{1}, {1, 0}} {
+		it := InterleaveWeighted([]Iterator[int]{Range(0, 3, 1), Range(0, 3, 1)}, weights)
+		if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("weights %v: got %v, want ErrInvalidArgument", weights, err)
+		}
+	}
+}
+
+func TestPriorityMergeStarvation(t *testing.T) {
+	sources := []Iterator[string]{
+		Iterate("h", func(s string) string { return s }),
+		Iterate("l", func(s string) string { return s }),
+	}
+	got, err := ToSlice(Limit(PriorityMerge(sources, []int{10, 1}, 3), 12))
+	want := []string{"h", "h", "h", "l", "h", "h", "h", "l", "h", "h", "h", "l"}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestPriorityMergeMostStarvedFirst(t *testing.T) {
+	sources := []Iterator[string]{
+		Iterate("h", func(s string) string { return s }),
+		Iterate("m", func(s string) string { return s }),
+		Iterate("l", func(s string) string { return s }),
+	}
+	got, err := ToSlice(Limit(PriorityMerge(sources, []int{10, 5, 1}, 2), 10))
+	want := []string{"h", "h", "m", "l", "h", "m", "l", "h", "m", "l"}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestPriorityMergeNoGuarantee(t *testing.T) {
+	sources := []Iterator[string]{
+		FromSlice([]string{"l1", "l2"}),
+		FromSlice([]string{"h1", "h2", "h3"}),
+	}
+	got, err := ToSlice(PriorityMerge(sources, []int{1, 10}, 0))
+	if want := []string{"h1", "h2", "h3", "l1", "l2"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if _, err := PriorityMerge(sources, []int{1}, 0)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestInterleaveUnequal(t *testing.T) {
+	it := Interleave(
+		FromSlice([]string{"a1", "a2", "a3"}),
+		FromSlice([]string{"b1"}),
+		FromSlice([]string{"c1", "c2"}),
+	)
+	got, err := ToSlice(it)
+	// The second turn stops at the exhausted b, after a2.
+	if want := []string{"a1", "b1", "c1", "a2"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestInterleaveSingle(t *testing.T) {
+	got, err := ToSlice(Interleave(Range(0, 3, 1)))
+	if want := []int{0, 1, 2}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if got, err := ToSlice(Interleave[int]()); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v without sources, want [], nil", got, err)
+	}
+}
+
+func TestInterleaveError(t *testing.T) {
+	failure := errors.New("failure")
+	it := Interleave(Range(0, 3, 1), Err[int](failure))
+	if v, err := it(); err != nil || v != 0 {
+		t.Fatalf("got %v, %v, want 0, nil", v, err)
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestRoundRobinUnequal(t *testing.T) {
+	it := RoundRobin(
+		FromSlice([]string{"a1", "a2", "a3", "a4"}),
+		Empty[string](),
+		FromSlice([]string{"b1"}),
+		FromSlice([]string{"c1", "c2"}),
+	)
+	got, err := ToSlice(it)
+	// The tail of the longest source is kept after the others are dropped.
+	if want := []string{"a1", "b1", "c1", "a2", "c2", "a3", "a4"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if got, err := ToSlice(RoundRobin[int]()); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v without sources, want an empty result", got, err)
+	}
+}
+
+func TestRoundRobinSafe(t *testing.T) {
+	it := RoundRobinSafe(Range(0, 1000, 1), Range(1000, 1500, 1), Range(1500, 3000, 1))
+	var (
+		mu  sync.Mutex
+		got []int
+		wg  sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := it()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				got = append(got, v)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	slices.Sort(got)
+	if len(got) != 3000 {
+		t.Fatalf("got %d elements, want 3000", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("got %d at %d after sorting, every element must appear once", v, i)
+		}
+	}
+}
+
+func TestMergeExactlyOnce(t *testing.T) {
+	got, err := ToSlice(Merge(context.Background(), Range(0, 1000, 1), Range(1000, 1500, 1), Empty[int](), Range(1500, 3000, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if len(got) != 3000 {
+		t.Fatalf("got %d elements, want 3000", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("got %d at %d after sorting, every element must appear once", v, i)
+		}
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	before := runtime.NumGoroutine()
+	failure := errors.New("failure")
+	it := Merge(context.Background(), Iterate(0, func(v int) int { return v + 1 }), Err[int](failure))
+	for {
+		_, err := it()
+		if errors.Is(err, failure) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got %v, want %v", err, failure)
+		}
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+	waitGoroutines(t, before)
+}
+
+func TestMergeCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	next := func(v int) int { return v + 1 }
+	it := Merge(ctx, Iterate(0, next), Iterate(0, next), Iterate(0, next))
+	for i := 0; i < 10; i++ {
+		if _, err := it(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	waitGoroutines(t, before)
+	for {
+		_, err := it()
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	}
+}
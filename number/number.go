@@ -0,0 +1,27 @@
+// Package number provides iteration tools for floating point numbers.
+package number
+
+import "github.com/zkksch/iter"
+
+// Linspace returns an iterator of n evenly spaced values from start to stop
+// including both ends, like linspace in numpy.
+// n == 1 yields only start, n <= 0 makes the iterator empty.
+//
+// Each value is computed from its index, so errors don't accumulate.
+func Linspace(start, stop float64, n int) iter.Iterator[float64] {
+	i := 0
+	return func() (float64, error) {
+		if i >= n {
+			return 0, iter.ErrStopIt
+		}
+		i++
+		switch {
+		case i == 1:
+			return start, nil
+		case i == n:
+			return stop, nil
+		default:
+			return start + float64(i-1)*(stop-start)/float64(n-1), nil
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package number
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zkksch/iter"
+)
+
+func TestLinspace(t *testing.T) {
+	got, err := iter.ToSlice(Linspace(0.1, 0.7, 7))
+	if err != nil || len(got) != 7 {
+		t.Fatalf("got %v, %v, want 7 values", got, err)
+	}
+	// The ends are exact, 0.1 + 6*0.1 isn't.
+	if got[0] != 0.1 || got[6] != 0.7 {
+		t.Fatalf("got ends %v and %v, want exactly 0.1 and 0.7", got[0], got[6])
+	}
+	for i, v := range got {
+		if want := 0.1 + float64(i)*0.1; math.Abs(v-want) > 1e-12 {
+			t.Fatalf("value %d: got %v, want %v", i, v, want)
+		}
+	}
+	if got, _ := iter.ToSlice(Linspace(1, 0, 3)); len(got) != 3 || got[1] != 0.5 {
+		t.Fatalf("got %v, want [1 0.5 0]", got)
+	}
+}
+
+func TestLinspaceSmallN(t *testing.T) {
+	if got, err := iter.ToSlice(Linspace(3, 5, 1)); err != nil || len(got) != 1 || got[0] != 3 {
+		t.Fatalf("got %v, %v, want [3], nil", got, err)
+	}
+	for _, n := range []int{0, -1} {
+		if got, err := iter.ToSlice(Linspace(3, 5, n)); err != nil || len(got) != 0 {
+			t.Fatalf("n %d: got %v, %v, want an empty result", n, got, err)
+		}
+	}
+}
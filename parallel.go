@@ -0,0 +1,306 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrStageNotOneToOne is returned by ParallelStage when the stage
+// doesn't produce exactly one element for every input element.
+var ErrStageNotOneToOne = errors.New("stage is not one to one")
+
+// ParallelStage returns an iterator running the stage over elements
+// of the source on several workers and preserving the order of the source.
+//
+// Elements of the source are distributed between the workers round-robin,
+// every worker runs its own instance of the stage over its part of the source.
+// The stage must produce exactly one element for every input element before
+// pulling the next one, otherwise ErrStageNotOneToOne is returned in the position
+// of the violation, no element after it is yielded.
+// The first error of the source, a stage or the context is returned
+// in its position and cancels all the workers.
+// At most workers+1 elements are pulled from the source ahead of the consumer.
+// If the iterator is abandoned before it stops the context must be cancelled
+// to release the goroutines. Non-positive number of workers
+// results in an iterator returning ErrInvalidArgument.
+func ParallelStage[T, K any](ctx context.Context, source Iterator[T], workers int,
+	stage func(Iterator[T]) Iterator[K]) Iterator[K] {
+	if workers <= 0 {
+		return Err[K](fmt.Errorf("%w: non-positive number of workers %d", ErrInvalidArgument, workers))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	inputs := make([]chan T, workers)
+	outputs := make([]chan result[K], workers)
+	for w := range inputs {
+		// Unbuffered channels keep at most an element per worker and one
+		// in the dispatcher pulled ahead of the consumer.
+		inputs[w] = make(chan T)
+		outputs[w] = make(chan result[K])
+	}
+
+	// The dispatcher sets total and sourceErr before closing finished.
+	var (
+		total     int
+		sourceErr error
+	)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer func() {
+			for _, input := range inputs {
+				close(input)
+			}
+		}()
+		for total = 0; ; total++ {
+			value, err := source()
+			if err != nil {
+				if !errors.Is(err, ErrStopIt) {
+					sourceErr = err
+				}
+				return
+			}
+			select {
+			case inputs[total%workers] <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		go func(input <-chan T, output chan<- result[K]) {
+			defer close(output)
+			consumed, produced := 0, 0
+			violated := false
+			send := func(r result[K]) bool {
+				select {
+				case output <- r:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			}
+			it := stage(func() (T, error) {
+				var empty T
+				if consumed > produced {
+					violated = true
+					return empty, ErrStageNotOneToOne
+				}
+				select {
+				case value, ok := <-input:
+					if !ok {
+						return empty, ErrStopIt
+					}
+					consumed++
+					return value, nil
+				case <-ctx.Done():
+					return empty, ctx.Err()
+				}
+			})
+			for {
+				value, err := it()
+				if violated {
+					send(result[K]{err: ErrStageNotOneToOne})
+					return
+				}
+				if errors.Is(err, ErrStopIt) {
+					if produced != consumed {
+						send(result[K]{err: ErrStageNotOneToOne})
+						return
+					}
+					// The stage may stop before its input is exhausted.
+					select {
+					case _, ok := <-input:
+						if ok {
+							send(result[K]{err: ErrStageNotOneToOne})
+						}
+					case <-ctx.Done():
+					}
+					return
+				}
+				if err != nil {
+					send(result[K]{err: err})
+					return
+				}
+				produced++
+				if produced != consumed {
+					send(result[K]{err: ErrStageNotOneToOne})
+					return
+				}
+				if !send(result[K]{value: value}) {
+					return
+				}
+			}
+		}(inputs[w], outputs[w])
+	}
+
+	position := 0
+	done := false
+	return func() (K, error) {
+		var empty K
+		if done {
+			return empty, ErrStopIt
+		}
+		var (
+			r  result[K]
+			ok bool
+		)
+		select {
+		case r, ok = <-outputs[position%workers]:
+		case <-ctx.Done():
+			r, ok = result[K]{err: ctx.Err()}, true
+		}
+		if !ok {
+			select {
+			case <-finished:
+			case <-ctx.Done():
+			}
+			switch {
+			case ctx.Err() != nil:
+				r.err = ctx.Err()
+			case position != total:
+				r.err = ErrStageNotOneToOne
+			case sourceErr != nil:
+				r.err = sourceErr
+			default:
+				r.err = ErrStopIt
+			}
+		}
+		if r.err != nil {
+			done = true
+			cancel()
+			return empty, r.err
+		}
+		position++
+		return r.value, nil
+	}
+}
+
+// ParallelMap is Map calling fn on several workers concurrently
+// and preserving the order of the source, see ParallelStage.
+// The first error of fn is returned in its position and cancels all the workers,
+// ErrStopIt returned by fn results in ErrStageNotOneToOne.
+func ParallelMap[T, K any](ctx context.Context, source Iterator[T], workers int, fn func(T) (K, error)) Iterator[K] {
+	return ParallelStage(ctx, source, workers, func(it Iterator[T]) Iterator[K] {
+		return Map(it, fn)
+	})
+}
+
+// ParallelFilter returns an iterator of elements of the source satisfying pred,
+// pred is called on several workers concurrently and the order of the source
+// is preserved, see ParallelMap. The first error of pred is returned
+// in its position and cancels all the workers.
+func ParallelFilter[T any](ctx context.Context, source Iterator[T], workers int, pred func(T) (bool, error)) Iterator[T] {
+	checked := ParallelMap(ctx, source, workers, func(value T) (Pair[T, bool], error) {
+		ok, err := pred(value)
+		return Pair[T, bool]{Left: value, Right: ok}, err
+	})
+	return func() (T, error) {
+		for {
+			p, err := checked()
+			if err != nil {
+				var empty T
+				return empty, err
+			}
+			if p.Right {
+				return p.Left, nil
+			}
+		}
+	}
+}
+
+// ParallelFilterUnordered is ParallelFilter yielding elements
+// in the order pred finishes with them instead of the order of the source.
+//
+// The first error of the source, pred or the context is returned
+// and cancels all the workers. If the iterator is abandoned before it stops
+// the context must be cancelled to release the goroutines.
+// Non-positive number of workers results in an iterator returning ErrInvalidArgument.
+func ParallelFilterUnordered[T any](ctx context.Context, source Iterator[T], workers int, pred func(T) (bool, error)) Iterator[T] {
+	if workers <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive number of workers %d", ErrInvalidArgument, workers))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	input := make(chan T)
+	output := make(chan result[T], workers)
+	send := func(r result[T]) bool {
+		select {
+		case output <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers + 1)
+	go func() {
+		defer wg.Done()
+		defer close(input)
+		for {
+			value, err := source()
+			if err != nil {
+				if !errors.Is(err, ErrStopIt) {
+					send(result[T]{err: err})
+				}
+				return
+			}
+			select {
+			case input <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for value := range input {
+				ok, err := pred(value)
+				if err != nil {
+					send(result[T]{err: err})
+					return
+				}
+				if ok && !send(result[T]{value: value}) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		var (
+			r  result[T]
+			ok bool
+		)
+		select {
+		case r, ok = <-output:
+		case <-ctx.Done():
+			r, ok = result[T]{err: ctx.Err()}, true
+		}
+		if !ok {
+			// The goroutines may finish because of the cancellation before it's noticed here.
+			r.err = ErrStopIt
+			if err := ctx.Err(); err != nil {
+				r.err = err
+			}
+		}
+		if r.err != nil {
+			done = true
+			cancel()
+			return empty, r.err
+		}
+		return r.value, nil
+	}
+}
@@ -0,0 +1,284 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallelStageOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	delays := make([]time.Duration, 100)
+	for i := range delays {
+		delays[i] = time.Duration(rng.Intn(500)) * time.Microsecond
+	}
+	got, err := ToSlice(ParallelStage(context.Background(), Range(0, 100, 1), 4, func(it Iterator[int]) Iterator[int] {
+		return Map(it, func(v int) (int, error) {
+			time.Sleep(delays[v])
+			return v * 2, nil
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range got {
+		if v != i*2 {
+			t.Fatalf("got %v at %d, want %d", v, i, i*2)
+		}
+	}
+	if len(got) != 100 {
+		t.Fatalf("got %d elements, want 100", len(got))
+	}
+}
+
+func TestParallelStageStageError(t *testing.T) {
+	failure := errors.New("failure")
+	it := ParallelStage(context.Background(), Range(0, 100, 1), 3, func(it Iterator[int]) Iterator[int] {
+		return Map(it, func(v int) (int, error) {
+			if v == 10 {
+				return 0, failure
+			}
+			return v, nil
+		})
+	})
+	for i := 0; i < 10; i++ {
+		if v, err := it(); err != nil || v != i {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestParallelStageSourceError(t *testing.T) {
+	failure := errors.New("failure")
+	source := Limit(Range(0, 100, 1), 5)
+	calls := 0
+	it := ParallelStage(context.Background(), func() (int, error) {
+		calls++
+		if calls > 5 {
+			return 0, failure
+		}
+		return source()
+	}, 2, func(it Iterator[int]) Iterator[int] { return it })
+	got, err := ToSlice(it)
+	if !errors.Is(err, failure) || got != nil {
+		t.Fatalf("got %v, %v, want nil, %v", got, err, failure)
+	}
+}
+
+func TestParallelStageNotOneToOne(t *testing.T) {
+	filter := func(it Iterator[int]) Iterator[int] {
+		return func() (int, error) {
+			for {
+				v, err := it()
+				if err != nil || v%3 != 2 {
+					return v, err
+				}
+			}
+		}
+	}
+	it := ParallelStage(context.Background(), Range(0, 10, 1), 2, filter)
+	var got []int
+	for {
+		v, err := it()
+		if errors.Is(err, ErrStageNotOneToOne) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got %v, want ErrStageNotOneToOne", err)
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{0, 1}) {
+		t.Fatalf("got %v before the error, want [0 1]", got)
+	}
+}
+
+func TestParallelStageTooManyElements(t *testing.T) {
+	double := func(it Iterator[int]) Iterator[int] {
+		var pending []int
+		return func() (int, error) {
+			if len(pending) > 0 {
+				v := pending[0]
+				pending = pending[1:]
+				return v, nil
+			}
+			v, err := it()
+			if err != nil {
+				return v, err
+			}
+			pending = append(pending, v)
+			return v, nil
+		}
+	}
+	_, err := ToSlice(ParallelStage(context.Background(), Range(0, 10, 1), 2, double))
+	if !errors.Is(err, ErrStageNotOneToOne) {
+		t.Fatalf("got %v, want ErrStageNotOneToOne", err)
+	}
+}
+
+func TestParallelStageCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := ParallelStage(ctx, Iterate(0, func(v int) int { return v + 1 }), 2,
+		func(it Iterator[int]) Iterator[int] { return it })
+	if _, err := it(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	for {
+		_, err := it()
+		if errors.Is(err, context.Canceled) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	}
+}
+
+func TestParallelStageInvalidWorkers(t *testing.T) {
+	_, err := ToSlice(ParallelStage(context.Background(), Range(0, 10, 1), 0,
+		func(it Iterator[int]) Iterator[int] { return it }))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestParallelMapOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	delays := make([]time.Duration, 200)
+	for i := range delays {
+		delays[i] = time.Duration(rng.Intn(300)) * time.Microsecond
+	}
+	got, err := ToSlice(ParallelMap(context.Background(), Range(0, 200, 1), 8, func(v int) (string, error) {
+		time.Sleep(delays[v])
+		return strconv.Itoa(v), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 200 {
+		t.Fatalf("got %d elements, want 200", len(got))
+	}
+	for i, v := range got {
+		if v != strconv.Itoa(i) {
+			t.Fatalf("got %q at %d, want %q", v, i, strconv.Itoa(i))
+		}
+	}
+}
+
+func TestParallelMapErrorPosition(t *testing.T) {
+	failure := errors.New("failure")
+	it := ParallelMap(context.Background(), Range(0, 100, 1), 4, func(v int) (int, error) {
+		if v == 30 {
+			return 0, failure
+		}
+		// Later elements finish before the failing one.
+		if v < 30 {
+			time.Sleep(100 * time.Microsecond)
+		}
+		return v, nil
+	})
+	for i := 0; i < 30; i++ {
+		if v, err := it(); err != nil || v != i {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestParallelMapBounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const workers = 4
+	var pulled atomic.Int64
+	source := Iterate(0, func(v int) int { return v + 1 })
+	it := ParallelMap(ctx, func() (int, error) {
+		pulled.Add(1)
+		return source()
+	}, workers, func(v int) (int, error) { return v, nil })
+	if _, err := it(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	// Every worker holds an element, the dispatcher holds one more.
+	if n := pulled.Load(); n > workers+2 {
+		t.Fatalf("%d elements pulled for 1 consumed, want at most %d", n, workers+2)
+	}
+}
+
+// waitGoroutines fails the test if the number of goroutines
+// doesn't drop to n within a second.
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines running, want %d", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestParallelFilterEarlyExit(t *testing.T) {
+	filters := map[string]func(context.Context, Iterator[int], int, func(int) (bool, error)) Iterator[int]{
+		"ordered":   ParallelFilter[int],
+		"unordered": ParallelFilterUnordered[int],
+	}
+	even := func(v int) (bool, error) { return v%2 == 0, nil }
+	for name, filter := range filters {
+		before := runtime.NumGoroutine()
+		ctx, cancel := context.WithCancel(context.Background())
+		it := filter(ctx, Iterate(0, func(v int) int { return v + 1 }), 4, even)
+		for i := 0; i < 10; i++ {
+			if v, err := it(); err != nil || v%2 != 0 {
+				t.Fatalf("%s: got %v, %v, want an even number, nil", name, v, err)
+			}
+		}
+		cancel()
+		waitGoroutines(t, before)
+		for {
+			_, err := it()
+			if errors.Is(err, context.Canceled) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: got %v after cancellation, want context.Canceled", name, err)
+			}
+		}
+
+		failure := errors.New("failure")
+		it = filter(context.Background(), Iterate(0, func(v int) int { return v + 1 }), 4, func(v int) (bool, error) {
+			if v == 50 {
+				return false, failure
+			}
+			return even(v)
+		})
+		for {
+			_, err := it()
+			if errors.Is(err, failure) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: got %v, want %v", name, err, failure)
+			}
+		}
+		waitGoroutines(t, before)
+	}
+}
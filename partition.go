@@ -0,0 +1,67 @@
+package iter
+
+import (
+	"errors"
+	"hash/maphash"
+	"sync"
+)
+
+// PartitionByKey returns n iterators splitting elements of the source
+// by the hash of their key, elements with equal keys go to the same iterator.
+//
+// The assignment of keys is stable within the call but differs between calls.
+// The iterators are safe for concurrent use and may be consumed at different rates,
+// elements pulled from the source for other iterators are buffered,
+// so the memory grows with the consumption skew.
+// An error of the source is returned once by every iterator, then they stop.
+// Non-positive n results in no iterators.
+func PartitionByKey[T any, K comparable](source Iterator[T], n int, key func(T) K) []Iterator[T] {
+	if n <= 0 {
+		return nil
+	}
+	seed := maphash.MakeSeed()
+	var (
+		mu       sync.Mutex
+		queues   = make([][]T, n)
+		reported = make([]bool, n)
+		done     bool
+		failure  error
+	)
+	shards := make([]Iterator[T], n)
+	for i := range shards {
+		shards[i] = func() (T, error) {
+			var empty T
+			mu.Lock()
+			defer mu.Unlock()
+			for {
+				if len(queues[i]) > 0 {
+					value := queues[i][0]
+					queues[i][0] = empty
+					queues[i] = queues[i][1:]
+					return value, nil
+				}
+				if done {
+					if failure != nil && !reported[i] {
+						reported[i] = true
+						return empty, failure
+					}
+					return empty, ErrStopIt
+				}
+				value, err := source()
+				if err != nil {
+					done = true
+					if !errors.Is(err, ErrStopIt) {
+						failure = err
+					}
+					continue
+				}
+				shard := int(maphash.Comparable(seed, key(value)) % uint64(n))
+				if shard == i {
+					return value, nil
+				}
+				queues[shard] = append(queues[shard], value)
+			}
+		}
+	}
+	return shards
+}
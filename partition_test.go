@@ -0,0 +1,108 @@
+package iter
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestPartitionByKeySameShard(t *testing.T) {
+	const n = 4
+	shards := PartitionByKey(Range(0, 10000, 1), n, func(v int) int { return v % 100 })
+	seen := make(map[int]int)
+	counts := make([]int, n)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := shard()
+				if errors.Is(err, ErrStopIt) {
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if s, ok := seen[v%100]; ok && s != i {
+					t.Errorf("key %d in shards %d and %d", v%100, s, i)
+				}
+				seen[v%100] = i
+				counts[i]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	total := 0
+	for i, c := range counts {
+		total += c
+		// 100 keys over 4 shards, a shard shouldn't get less than half of its share.
+		if c < 10000/n/2 {
+			t.Errorf("shard %d got %d elements of 10000", i, c)
+		}
+	}
+	if total != 10000 {
+		t.Fatalf("got %d elements, want 10000", total)
+	}
+}
+
+func TestPartitionByKeyError(t *testing.T) {
+	failure := errors.New("failure")
+	shards := PartitionByKey(Err[int](failure), 3, func(v int) int { return v })
+	for i, shard := range shards {
+		if _, err := shard(); !errors.Is(err, failure) {
+			t.Errorf("shard %d: got %v, want %v", i, err, failure)
+		}
+		if _, err := shard(); !errors.Is(err, ErrStopIt) {
+			t.Errorf("shard %d: got %v after the error, want ErrStopIt", i, err)
+		}
+	}
+	if PartitionByKey(Range(0, 3, 1), 0, func(v int) int { return v }) != nil {
+		t.Error("got iterators for n = 0")
+	}
+}
+
+func TestPartitionByKeyEqualKeys(t *testing.T) {
+	type weighted struct {
+		name   string
+		weight float64
+	}
+	negZero := math.Copysign(0, -1)
+	var input []weighted
+	for i := 0; i < 50; i++ {
+		name := string(rune('a' + i%26))
+		input = append(input, weighted{name, 0}, weighted{name, negZero})
+	}
+	const n = 8
+	floatShards := PartitionByKey(FromSlice(input), n, func(w weighted) float64 { return w.weight })
+	structShards := PartitionByKey(FromSlice(input), n, func(w weighted) weighted { return w })
+	floatSeen := make(map[float64]int)
+	structSeen := make(map[weighted]int)
+	for i := 0; i < n; i++ {
+		floats, err := ToSlice(floatShards[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range floats {
+			if s, ok := floatSeen[w.weight]; ok && s != i {
+				t.Fatalf("key %v in shards %d and %d", w.weight, s, i)
+			}
+			floatSeen[w.weight] = i
+		}
+		structs, err := ToSlice(structShards[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range structs {
+			if s, ok := structSeen[w]; ok && s != i {
+				t.Fatalf("key %v in shards %d and %d", w, s, i)
+			}
+			structSeen[w] = i
+		}
+	}
+}
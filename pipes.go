@@ -0,0 +1,435 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+)
+
+// TakeWhile returns an iterator yielding elements of the source
+// while pred returns true for them.
+// The iterator stops on the first element failing pred
+// and doesn't call the source anymore.
+func TakeWhile[T any](source Iterator[T], pred func(T) bool) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err != nil {
+			return empty, err
+		}
+		if !pred(value) {
+			done = true
+			return empty, ErrStopIt
+		}
+		return value, nil
+	}
+}
+
+// DropWhile returns an iterator dropping elements of the source
+// while pred returns true for them, the first element failing pred
+// and all the following elements are yielded unconditionally.
+func DropWhile[T any](source Iterator[T], pred func(T) bool) Iterator[T] {
+	dropping := true
+	return func() (T, error) {
+		if !dropping {
+			return source()
+		}
+		for {
+			value, err := source()
+			if err != nil {
+				return value, err
+			}
+			if !pred(value) {
+				dropping = false
+				return value, nil
+			}
+		}
+	}
+}
+
+// StepBy returns an iterator yielding the first element of the source
+// and then every step-th element, the skipped elements are consumed.
+// Non-positive step results in an iterator returning ErrInvalidArgument.
+func StepBy[T any](source Iterator[T], step int) Iterator[T] {
+	if step <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive step %d", ErrInvalidArgument, step))
+	}
+	first := true
+	return func() (T, error) {
+		if first {
+			first = false
+			return source()
+		}
+		for i := 1; i < step; i++ {
+			if value, err := source(); err != nil {
+				return value, err
+			}
+		}
+		return source()
+	}
+}
+
+// StepBySafe works as StepBy and is safe for concurrent use.
+func StepBySafe[T any](source Iterator[T], step int) Iterator[T] {
+	return safe(StepBy(source, step))
+}
+
+// Stateful returns an iterator mapping elements of the source with fn
+// which receives the state returned by its previous call, or initial on the first call.
+//
+// An error from fn is returned and stops the iterator. Errors of the source
+// are returned without calling fn, so the state doesn't change.
+func Stateful[T, S, K any](source Iterator[T], initial S, fn func(state S, el T) (K, S, error)) Iterator[K] {
+	state := initial
+	done := false
+	return func() (K, error) {
+		var empty K
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err != nil {
+			return empty, err
+		}
+		mapped, next, err := fn(state, value)
+		if err != nil {
+			done = true
+			return empty, err
+		}
+		state = next
+		return mapped, nil
+	}
+}
+
+// StatefulSafe works as Stateful and is safe for concurrent use,
+// the state transitions are serialized.
+func StatefulSafe[T, S, K any](source Iterator[T], initial S, fn func(state S, el T) (K, S, error)) Iterator[K] {
+	return safe(Stateful(source, initial, fn))
+}
+
+// Enumerate returns an iterator pairing elements of the source
+// with their zero-based position in the resulting iterator.
+// Calls ending with an error don't take a position.
+func Enumerate[T any](source Iterator[T]) Iterator[Pair[int, T]] {
+	i := 0
+	return func() (Pair[int, T], error) {
+		value, err := source()
+		if err != nil {
+			return Pair[int, T]{}, err
+		}
+		i++
+		return Pair[int, T]{i - 1, value}, nil
+	}
+}
+
+// EnumerateSafe works as Enumerate and is safe for concurrent use,
+// every position is paired with the element returned by the same call.
+func EnumerateSafe[T any](source Iterator[T]) Iterator[Pair[int, T]] {
+	return safe(Enumerate(source))
+}
+
+// Flatten returns an iterator over elements of the slices of the source,
+// the next slice is pulled from the source when the current one is exhausted.
+// Empty slices are skipped.
+func Flatten[T any](source Iterator[[]T]) Iterator[T] {
+	var current []T
+	return func() (T, error) {
+		for len(current) == 0 {
+			next, err := source()
+			if err != nil {
+				var empty T
+				return empty, err
+			}
+			current = next
+		}
+		value := current[0]
+		current = current[1:]
+		return value, nil
+	}
+}
+
+// FlattenIterators returns an iterator over elements of the iterators of the source,
+// the next iterator is pulled from the source when the current one stops.
+// A failure of the source or of an inner iterator is returned and stops the iterator.
+func FlattenIterators[T any](source Iterator[Iterator[T]]) Iterator[T] {
+	var (
+		current Iterator[T]
+		done    bool
+	)
+	return func() (T, error) {
+		var empty T
+		for !done {
+			if current == nil {
+				next, err := source()
+				if err != nil {
+					done = true
+					return empty, err
+				}
+				current = next
+			}
+			value, err := current()
+			if errors.Is(err, ErrStopIt) {
+				current = nil
+				continue
+			}
+			if err != nil {
+				done = true
+				return empty, err
+			}
+			return value, nil
+		}
+		return empty, ErrStopIt
+	}
+}
+
+// FlatMap returns an iterator over elements of the iterators returned by fn
+// for elements of the source, they are drained lazily one after another.
+//
+// ErrStopIt returned by fn stops the iterator,
+// other errors of fn and inner iterators are returned as is.
+func FlatMap[T, K any](source Iterator[T], fn func(T) (Iterator[K], error)) Iterator[K] {
+	var (
+		current Iterator[K]
+		done    bool
+	)
+	return func() (K, error) {
+		var empty K
+		for !done {
+			if current == nil {
+				value, err := source()
+				if err != nil {
+					return empty, err
+				}
+				next, err := fn(value)
+				if errors.Is(err, ErrStopIt) {
+					done = true
+					break
+				}
+				if err != nil {
+					return empty, err
+				}
+				current = next
+			}
+			value, err := current()
+			if errors.Is(err, ErrStopIt) {
+				current = nil
+				continue
+			}
+			return value, err
+		}
+		return empty, ErrStopIt
+	}
+}
+
+// Scan returns an iterator of intermediate results of folding the source with fn,
+// it yields the accumulator after every element, the initial value isn't yielded.
+// Errors of the source are returned and don't change the accumulator.
+func Scan[T, K any](source Iterator[T], init K, fn func(T, K) K) Iterator[K] {
+	acc := init
+	return func() (K, error) {
+		value, err := source()
+		if err != nil {
+			var empty K
+			return empty, err
+		}
+		acc = fn(value, acc)
+		return acc, nil
+	}
+}
+
+// Inspect returns an iterator passing through elements of the source
+// and calling fn for every successfully produced element.
+func Inspect[T any](source Iterator[T], fn func(T)) Iterator[T] {
+	return func() (T, error) {
+		value, err := source()
+		if err == nil {
+			fn(value)
+		}
+		return value, err
+	}
+}
+
+// Map returns an iterator of elements of the source mapped with fn.
+// ErrStopIt returned by fn stops the iterator, other errors of fn are returned as is.
+func Map[T, K any](source Iterator[T], fn func(T) (K, error)) Iterator[K] {
+	done := false
+	return func() (K, error) {
+		var empty K
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err != nil {
+			return empty, err
+		}
+		mapped, err := fn(value)
+		if errors.Is(err, ErrStopIt) {
+			done = true
+		}
+		return mapped, err
+	}
+}
+
+// MapOrStats counts elements substituted by MapOr and MapOrElse.
+// It's safe to read concurrently with the iteration.
+type MapOrStats struct {
+	substituted atomic.Int64
+}
+
+// Substituted returns the number of substituted elements.
+func (s *MapOrStats) Substituted() int {
+	return int(s.substituted.Load())
+}
+
+// MapOr works as Map but substitutes fallback for elements fn fails on and continues.
+// ErrStopIt returned by fn still stops the iterator.
+func MapOr[T, K any](source Iterator[T], fn func(T) (K, error), fallback K) (Iterator[K], *MapOrStats) {
+	return MapOrElse(source, fn, func(error, T) K { return fallback })
+}
+
+// MapOrElse works as MapOr deriving the substitute from the error and the element.
+func MapOrElse[T, K any](source Iterator[T], fn func(T) (K, error), fallback func(error, T) K) (Iterator[K], *MapOrStats) {
+	stats := &MapOrStats{}
+	return Map(source, func(value T) (K, error) {
+		mapped, err := fn(value)
+		if err != nil && !errors.Is(err, ErrStopIt) {
+			stats.substituted.Add(1)
+			return fallback(err, value), nil
+		}
+		return mapped, err
+	}), stats
+}
+
+// Intersperse returns an iterator yielding sep between every two consecutive
+// elements of the source. It looks one element ahead, so the separator
+// is yielded only when the next element exists.
+func Intersperse[T any](source Iterator[T], sep T) Iterator[T] {
+	var (
+		next    T
+		hasNext bool
+		started bool
+	)
+	return func() (T, error) {
+		if hasNext {
+			hasNext = false
+			return next, nil
+		}
+		value, err := source()
+		if err != nil {
+			return value, err
+		}
+		if !started {
+			started = true
+			return value, nil
+		}
+		next, hasNext = value, true
+		return sep, nil
+	}
+}
+
+// buffered returns an iterator draining the source on the first call
+// and then yielding elements prepared by fn from the drained ones.
+// An error during draining is returned by the first call and stops the iterator.
+func buffered[T any](source Iterator[T], fn func([]T) []T) Iterator[T] {
+	var (
+		values  []T
+		failure error
+		drained bool
+	)
+	return func() (T, error) {
+		var empty T
+		if !drained {
+			drained = true
+			all, err := ToSlice(source)
+			if err != nil {
+				failure = err
+			} else {
+				values = fn(all)
+			}
+		}
+		if failure != nil {
+			err := failure
+			failure = nil
+			return empty, err
+		}
+		if len(values) == 0 {
+			return empty, ErrStopIt
+		}
+		value := values[0]
+		values[0] = empty
+		values = values[1:]
+		return value, nil
+	}
+}
+
+// Reverse returns an iterator over elements of the source in reverse order.
+// The source is drained into memory on the first call, so it must be finite,
+// limit an infinite source with Limit before reversing it.
+// An error during draining is returned by the first call and stops the iterator.
+func Reverse[T any](source Iterator[T]) Iterator[T] {
+	return buffered(source, func(values []T) []T {
+		for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+			values[i], values[j] = values[j], values[i]
+		}
+		return values
+	})
+}
+
+// Limit returns an iterator yielding at most n elements of the source.
+func Limit[T any](source Iterator[T], n int) Iterator[T] {
+	return func() (T, error) {
+		if n <= 0 {
+			var empty T
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err == nil {
+			n--
+		}
+		return value, err
+	}
+}
+
+// LimitSafe works as Limit and is safe for concurrent use,
+// all the consumers together get at most n elements.
+func LimitSafe[T any](source Iterator[T], n int) Iterator[T] {
+	return safe(Limit(source, n))
+}
+
+// Shuffle returns an iterator over elements of the source in random order
+// made with the Fisher-Yates shuffle, nil rng means the global source of math/rand.
+// The source is drained into memory on the first call, so infinite sources
+// aren't supported, use SampleByKey to sample a stream without buffering it.
+// An error during draining is returned before any element is yielded.
+func Shuffle[T any](source Iterator[T], rng *rand.Rand) Iterator[T] {
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+	return buffered(source, func(values []T) []T {
+		for i := len(values) - 1; i > 0; i-- {
+			j := intn(i + 1)
+			values[i], values[j] = values[j], values[i]
+		}
+		return values
+	})
+}
+
+// WithContext returns an iterator checking the context before every pull
+// from the source. Once the context is done its error is returned,
+// not ErrStopIt, so finalizers report the cancellation as a failure.
+func WithContext[T any](ctx context.Context, source Iterator[T]) Iterator[T] {
+	return func() (T, error) {
+		if err := ctx.Err(); err != nil {
+			var empty T
+			return empty, err
+		}
+		return source()
+	}
+}
@@ -0,0 +1,550 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReverse(t *testing.T) {
+	got, err := ToSlice(Reverse(Range(0, 5, 1)))
+	if want := []int{4, 3, 2, 1, 0}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	got, err = ToSlice(Reverse(Empty[int]()))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v, want [], nil", got, err)
+	}
+}
+
+func TestReverseLimitInfinite(t *testing.T) {
+	// Limit makes an infinite source safe to reverse.
+	got, err := ToSlice(Reverse(Limit(Iterate(0, func(v int) int { return v + 1 }), 5)))
+	if want := []int{4, 3, 2, 1, 0}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestReverseError(t *testing.T) {
+	failure := errors.New("failure")
+	calls := 0
+	it := Reverse(Map(Range(0, 5, 1), func(v int) (int, error) {
+		calls++
+		if v == 3 {
+			return 0, failure
+		}
+		return v, nil
+	}))
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	drained := calls
+	for i := 0; i < 2; i++ {
+		if _, err := it(); !errors.Is(err, ErrStopIt) {
+			t.Fatalf("got %v after the error, want ErrStopIt", err)
+		}
+	}
+	if calls != drained {
+		t.Fatalf("source called %d times after the error", calls-drained)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	calls := 0
+	source := Map(Range(0, 10, 1), func(v int) (int, error) {
+		calls++
+		return v, nil
+	})
+	got, err := ToSlice(Limit(source, 3))
+	if want := []int{0, 1, 2}; err != nil || !slices.Equal(got, want) || calls != 3 {
+		t.Fatalf("got %v, %v after %d calls, want %v, nil after 3", got, err, calls, want)
+	}
+	got, err = ToSlice(Limit(Range(0, 2, 1), 5))
+	if want := []int{0, 1}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if got, err := ToSlice(Limit(Range(0, 2, 1), 0)); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v, want [], nil", got, err)
+	}
+}
+
+func TestLimitSafe(t *testing.T) {
+	var calls atomic.Int64
+	source := func() (int, error) {
+		return int(calls.Add(1)), nil
+	}
+	it := LimitSafe(source, 1000)
+	var (
+		total atomic.Int64
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if _, err := it(); err != nil {
+					if !errors.Is(err, ErrStopIt) {
+						t.Error(err)
+					}
+					return
+				}
+				total.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if total.Load() != 1000 || calls.Load() != 1000 {
+		t.Fatalf("got %d elements after %d pulls, want 1000 after 1000", total.Load(), calls.Load())
+	}
+}
+
+func TestStepBy(t *testing.T) {
+	got, err := ToSlice(StepBy(Range(0, 10, 1), 3))
+	if want := []int{0, 3, 6, 9}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	got, err = ToSlice(StepBy(Range(0, 5, 1), 1))
+	if want := []int{0, 1, 2, 3, 4}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("step 1: got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestStepByInfinite(t *testing.T) {
+	got, err := ToSlice(Limit(StepBy(Iterate(0, func(v int) int { return v + 1 }), 5), 4))
+	if want := []int{0, 5, 10, 15}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestStepByInvalid(t *testing.T) {
+	for _, step := range []int{0, -1} {
+		if _, err := StepBy(Range(0, 3, 1), step)(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("step %d: got %v, want ErrInvalidArgument", step, err)
+		}
+	}
+}
+
+func TestStepBySafe(t *testing.T) {
+	it := StepBySafe(Range(0, 3000, 1), 3)
+	var (
+		mu   sync.Mutex
+		seen []int
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := it()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				seen = append(seen, v)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	slices.Sort(seen)
+	for i, v := range seen {
+		if v != i*3 {
+			t.Fatalf("got %d at %d, want %d", v, i, i*3)
+		}
+	}
+	if len(seen) != 1000 {
+		t.Fatalf("got %d elements, want 1000", len(seen))
+	}
+}
+
+func TestStatefulDeltaEncoding(t *testing.T) {
+	it := Stateful(FromSlice([]int{10, 12, 15, 15, 11}), 0, func(prev, v int) (int, int, error) {
+		return v - prev, v, nil
+	})
+	got, err := ToSlice(it)
+	if want := []int{10, 2, 3, 0, -4}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestStatefulToggleFilter(t *testing.T) {
+	// Elements between "on" and "off" markers are kept, others are marked as dropped.
+	it := Stateful(FromSlice([]string{"a", "on", "b", "c", "off", "d", "on", "e"}), false,
+		func(on bool, v string) (string, bool, error) {
+			switch {
+			case v == "on":
+				return "", true, nil
+			case v == "off":
+				return "", false, nil
+			case on:
+				return v, on, nil
+			}
+			return "", on, nil
+		})
+	var got []string
+	for {
+		v, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != "" {
+			got = append(got, v)
+		}
+	}
+	if want := []string{"b", "c", "e"}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestStatefulError(t *testing.T) {
+	failure := errors.New("failure")
+	it := Stateful(Range(0, 10, 1), 0, func(sum, v int) (int, int, error) {
+		if v == 3 {
+			return 0, sum, failure
+		}
+		return sum + v, sum + v, nil
+	})
+	for _, want := range []int{0, 1, 3} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestEnumerateAfterFilter(t *testing.T) {
+	// Indices count the elements left by the filter, not the source ones.
+	it := Enumerate(Distinct(FromSlice([]string{"a", "b", "a", "c", "b", "d"})))
+	got, err := ToSlice(it)
+	want := []Pair[int, string]{{0, "a"}, {1, "b"}, {2, "c"}, {3, "d"}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestEnumerateSkipsErrors(t *testing.T) {
+	failure := errors.New("failure")
+	it := Enumerate(Map(Range(0, 5, 1), func(v int) (int, error) {
+		if v%2 == 1 {
+			return 0, failure
+		}
+		return v, nil
+	}))
+	var got []Pair[int, int]
+	for {
+		p, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if errors.Is(err, failure) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	if want := []Pair[int, int]{{0, 0}, {1, 2}, {2, 4}}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFlattenIterators(t *testing.T) {
+	it := FlattenIterators(FromSlice([]Iterator[int]{
+		Empty[int](),
+		Range(0, 2, 1),
+		Empty[int](),
+		Empty[int](),
+		Range(5, 7, 1),
+		Empty[int](),
+	}))
+	got, err := ToSlice(it)
+	if want := []int{0, 1, 5, 6}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestFlattenIteratorsInnerError(t *testing.T) {
+	failure := errors.New("failure")
+	pulled := false
+	it := FlattenIterators(FromSlice([]Iterator[int]{
+		Range(0, 2, 1),
+		Empty[int](),
+		Err[int](failure),
+		func() (int, error) {
+			pulled = true
+			return 9, nil
+		},
+	}))
+	for _, want := range []int{0, 1} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) || pulled {
+		t.Fatalf("got %v, next iterator pulled %v, want ErrStopIt and no pull", err, pulled)
+	}
+}
+
+func TestFlattenIteratorsOuterError(t *testing.T) {
+	failure := errors.New("failure")
+	if _, err := ToSlice(FlattenIterators(Err[Iterator[int]](failure))); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if got, err := ToSlice(FlattenIterators(Empty[Iterator[int]]())); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v, want [], nil", got, err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	failure := errors.New("failure")
+	var seen []int
+	it := Inspect(Map(Range(0, 6, 1), func(v int) (int, error) {
+		if v == 2 {
+			return 0, failure
+		}
+		return v, nil
+	}), func(v int) { seen = append(seen, v) })
+	var got []int
+	for {
+		v, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err == nil {
+			got = append(got, v)
+		}
+	}
+	if want := []int{0, 1, 3, 4, 5}; !slices.Equal(got, want) || !slices.Equal(seen, want) {
+		t.Fatalf("got %v, inspected %v, want %v for both", got, seen, want)
+	}
+}
+
+func TestInspectCalledPerPull(t *testing.T) {
+	calls := 0
+	it := Limit(Inspect(Range(0, 100, 1), func(int) { calls++ }), 3)
+	if _, err := ToSlice(it); err != nil || calls != 3 {
+		t.Fatalf("got %v, %d calls, want nil, 3", err, calls)
+	}
+}
+
+func TestMapOrAlignment(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e"}
+	raw := []string{"1", "x", "3", "", "5"}
+	parsed, stats := MapOr(FromSlice(raw), strconv.Atoi, -1)
+	// The parsed values stay aligned with the ids despite the failures.
+	got, err := ToSlice(PairsLongest(FromSlice(ids), parsed, "?", -2))
+	want := []Pair[string, int]{{"a", 1}, {"b", -1}, {"c", 3}, {"d", -1}, {"e", 5}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if stats.Substituted() != 2 {
+		t.Fatalf("got %d substitutions, want 2", stats.Substituted())
+	}
+}
+
+func TestMapOrElse(t *testing.T) {
+	it, stats := MapOrElse(FromSlice([]string{"1", "x", "3"}), strconv.Atoi, func(err error, s string) int {
+		return -len(s)
+	})
+	got, err := ToSlice(it)
+	if want := []int{1, -1, 3}; err != nil || !slices.Equal(got, want) || stats.Substituted() != 1 {
+		t.Fatalf("got %v, %v, %d substitutions, want %v, nil, 1", got, err, stats.Substituted(), want)
+	}
+}
+
+func TestMapOrStop(t *testing.T) {
+	it, stats := MapOr(Range(0, 10, 1), func(v int) (int, error) {
+		if v == 3 {
+			return 0, ErrStopIt
+		}
+		return v, nil
+	}, -1)
+	got, err := ToSlice(it)
+	if want := []int{0, 1, 2}; err != nil || !slices.Equal(got, want) || stats.Substituted() != 0 {
+		t.Fatalf("got %v, %v, %d substitutions, want %v, nil, 0", got, err, stats.Substituted(), want)
+	}
+}
+
+func TestIntersperse(t *testing.T) {
+	for _, tc := range []struct {
+		input []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b", "c"}, "a, b, c"},
+	} {
+		got, err := ToSlice(Intersperse(FromSlice(tc.input), ", "))
+		if err != nil || strings.Join(got, "") != tc.want {
+			t.Errorf("%v: got %q, %v, want %q, nil", tc.input, got, err, tc.want)
+		}
+	}
+}
+
+func TestIntersperseComposition(t *testing.T) {
+	// Separators go between the mapped elements left by the filter.
+	words := Map(Distinct(FromSlice([]string{"go", "is", "go", "fun"})), func(s string) (string, error) {
+		return strings.ToUpper(s), nil
+	})
+	var b strings.Builder
+	it := Intersperse(words, "-")
+	for {
+		s, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.WriteString(s)
+	}
+	if b.String() != "GO-IS-FUN" {
+		t.Fatalf("got %q, want GO-IS-FUN", b.String())
+	}
+}
+
+func TestIntersperseError(t *testing.T) {
+	failure := errors.New("failure")
+	it := Intersperse(ChainLazy(FromSlice([]Iterator[int]{Range(1, 3, 1), Err[int](failure)})), 0)
+	for _, want := range []int{1, 0, 2} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestShuffleSeeded(t *testing.T) {
+	input := make([]int, 100)
+	for i := range input {
+		input[i] = i
+	}
+	first, err := ToSlice(Shuffle(FromSlice(slices.Clone(input)), rand.New(rand.NewSource(5))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := ToSlice(Shuffle(FromSlice(slices.Clone(input)), rand.New(rand.NewSource(5))))
+	if !slices.Equal(first, second) {
+		t.Fatal("the same seed gave different orders")
+	}
+	if slices.Equal(first, input) {
+		t.Fatal("the elements weren't shuffled")
+	}
+	sorted := slices.Clone(first)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, input) {
+		t.Fatalf("shuffle changed the elements: %v", first)
+	}
+}
+
+func TestShuffleError(t *testing.T) {
+	failure := errors.New("failure")
+	it := Shuffle(ChainLazy(FromSlice([]Iterator[int]{Range(0, 5, 1), Err[int](failure)})), nil)
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestWithContextCancelMidway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	source := Range(0, 1000, 1)
+	pulled := 0
+	got, err := ToSlice(WithContext(ctx, func() (int, error) {
+		v, err := source()
+		if pulled++; pulled == 10 {
+			cancel()
+		}
+		return v, err
+	}))
+	if !errors.Is(err, context.Canceled) || got != nil {
+		t.Fatalf("got %v, %v, want nil, context.Canceled", got, err)
+	}
+	if pulled != 10 {
+		t.Fatalf("source pulled %d times, want 10", pulled)
+	}
+}
+
+func TestDropWhile(t *testing.T) {
+	less := func(n int) func(int) bool { return func(v int) bool { return v < n } }
+	got, err := ToSlice(DropWhile(FromSlice([]int{1, 2, 5, 1, 6}), less(3)))
+	// The first failing element is kept, later elements aren't checked.
+	if err != nil || !slices.Equal(got, []int{5, 1, 6}) {
+		t.Fatalf("got %v, %v, want [5 1 6], nil", got, err)
+	}
+	if got, err = ToSlice(DropWhile(Range(0, 10, 1), less(100))); err != nil || len(got) != 0 {
+		t.Fatalf("got %v, %v, want an empty result when every element matches", got, err)
+	}
+	if got, err = ToSlice(DropWhile(Range(5, 10, 1), less(5))); err != nil || !slices.Equal(got, []int{5, 6, 7, 8, 9}) {
+		t.Fatalf("got %v, %v, want the source when the first element fails", got, err)
+	}
+	failure := errors.New("failure")
+	it := DropWhile(ChainLazy(FromSlice([]Iterator[int]{Range(0, 2, 1), Err[int](failure)})), less(100))
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v while dropping, want %v", err, failure)
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	// Odd elements expand to nothing, which must not end the iteration.
+	it := FlatMap(Range(0, 6, 1), func(v int) (Iterator[int], error) {
+		if v%2 == 1 {
+			return Empty[int](), nil
+		}
+		return RepeatN(v, 2), nil
+	})
+	got, err := ToSlice(it)
+	if want := []int{0, 0, 2, 2, 4, 4}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestFlatMapStop(t *testing.T) {
+	pulled := 0
+	source := Range(0, 10, 1)
+	it := FlatMap(func() (int, error) {
+		pulled++
+		return source()
+	}, func(v int) (Iterator[int], error) {
+		if v == 2 {
+			return nil, ErrStopIt
+		}
+		return RepeatN(v, 1), nil
+	})
+	got, err := ToSlice(it)
+	if err != nil || !slices.Equal(got, []int{0, 1}) {
+		t.Fatalf("got %v, %v, want [0 1], nil", got, err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) || pulled != 3 {
+		t.Fatalf("got %v after %d pulls, want ErrStopIt after 3", err, pulled)
+	}
+	failure := errors.New("failure")
+	it = FlatMap(Range(0, 3, 1), func(v int) (Iterator[int], error) { return nil, failure })
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
@@ -0,0 +1,54 @@
+package iter
+
+import "errors"
+
+// Result is an element of a sequence that can be a value or an error.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Ok returns a successful Result with the value.
+func Ok[T any](value T) Result[T] {
+	return Result[T]{Value: value}
+}
+
+// ErrResult returns a failed Result with the error,
+// it isn't named Err, as Err is the failing iterator source.
+func ErrResult[T any](err error) Result[T] {
+	return Result[T]{Err: err}
+}
+
+// AsResults returns an iterator of results of the source,
+// errors of the source are yielded as failed results
+// and the source is called again on the next call.
+func AsResults[T any](source Iterator[T]) Iterator[Result[T]] {
+	return func() (Result[T], error) {
+		value, err := source()
+		if errors.Is(err, ErrStopIt) {
+			return Result[T]{}, ErrStopIt
+		}
+		return Result[T]{Value: value, Err: err}, nil
+	}
+}
+
+// FromResults returns an iterator of values of the results,
+// the first failed result is returned as an error and stops the iterator.
+func FromResults[T any](source Iterator[Result[T]]) Iterator[T] {
+	done := false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		result, err := source()
+		if err == nil {
+			err = result.Err
+		}
+		if err != nil {
+			done = true
+			return empty, err
+		}
+		return result.Value, nil
+	}
+}
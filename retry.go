@@ -0,0 +1,58 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryMap is Map calling fn up to attempts times for every element,
+// waiting backoff(attempt) before every next attempt, attempt starts at 1.
+// The error of the last attempt is returned wrapped with the number of attempts.
+// ErrStopIt returned by fn isn't retried and stops the iterator.
+// Nil backoff with more than one attempt results in an iterator returning ErrInvalidArgument.
+func RetryMap[T, K any](source Iterator[T], fn func(T) (K, error), attempts int, backoff func(attempt int) time.Duration) Iterator[K] {
+	return RetryMapCtx(context.Background(), source, fn, attempts, backoff)
+}
+
+// RetryMapCtx is RetryMap interrupting the backoff when the context is cancelled,
+// in that case the error of the context is returned and stops the iterator.
+func RetryMapCtx[T, K any](ctx context.Context, source Iterator[T], fn func(T) (K, error), attempts int, backoff func(attempt int) time.Duration) Iterator[K] {
+	attempts = max(attempts, 1)
+	if attempts > 1 && backoff == nil {
+		return Err[K](fmt.Errorf("%w: nil backoff for %d attempts", ErrInvalidArgument, attempts))
+	}
+	done := false
+	return func() (K, error) {
+		var empty K
+		if done {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err != nil {
+			return empty, err
+		}
+		for attempt := 1; ; attempt++ {
+			mapped, err := fn(value)
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				return empty, ErrStopIt
+			}
+			if err == nil {
+				return mapped, nil
+			}
+			if attempt == attempts {
+				return empty, fmt.Errorf("after %d attempts: %w", attempts, err)
+			}
+			timer := time.NewTimer(backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				done = true
+				return empty, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
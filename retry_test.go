@@ -0,0 +1,92 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// flaky returns fn failing the first failures calls for every element.
+func flaky(failures int, err error) (fn func(int) (int, error), calls map[int]int) {
+	calls = make(map[int]int)
+	return func(v int) (int, error) {
+		calls[v]++
+		if calls[v] <= failures {
+			return 0, err
+		}
+		return v * 10, nil
+	}, calls
+}
+
+func TestRetryMap(t *testing.T) {
+	failure := errors.New("failure")
+	fn, calls := flaky(2, failure)
+	var waits []int
+	got, err := ToSlice(RetryMap(Range(0, 3, 1), fn, 3, func(attempt int) time.Duration {
+		waits = append(waits, attempt)
+		return 0
+	}))
+	if err != nil || !slices.Equal(got, []int{0, 10, 20}) {
+		t.Fatalf("got %v, %v, want [0 10 20], nil", got, err)
+	}
+	if calls[0] != 3 || !slices.Equal(waits, []int{1, 2, 1, 2, 1, 2}) {
+		t.Fatalf("got %d calls and backoffs for attempts %v", calls[0], waits)
+	}
+}
+
+func TestRetryMapExhausted(t *testing.T) {
+	failure := errors.New("failure")
+	fn, calls := flaky(5, failure)
+	it := RetryMap(Range(0, 2, 1), fn, 3, func(int) time.Duration { return 0 })
+	_, err := it()
+	if !errors.Is(err, failure) || err.Error() != "after 3 attempts: failure" {
+		t.Fatalf("got %v, want %v wrapped with the number of attempts", err, failure)
+	}
+	if calls[0] != 3 {
+		t.Fatalf("fn called %d times, want 3", calls[0])
+	}
+	// The error doesn't stop the iterator.
+	if _, err := it(); !errors.Is(err, failure) || calls[1] != 3 {
+		t.Fatalf("got %v after %d calls for the next element", err, calls[1])
+	}
+}
+
+func TestRetryMapStop(t *testing.T) {
+	calls := 0
+	it := RetryMap(Range(0, 5, 1), func(v int) (int, error) {
+		calls++
+		return 0, ErrStopIt
+	}, 3, func(int) time.Duration { return 0 })
+	if _, err := it(); !errors.Is(err, ErrStopIt) || calls != 1 {
+		t.Fatalf("got %v after %d calls, want ErrStopIt without retries", err, calls)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) || calls != 1 {
+		t.Fatalf("got %v after %d calls, want ErrStopIt and no more calls", err, calls)
+	}
+}
+
+func TestRetryMapCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fn, _ := flaky(5, errors.New("failure"))
+	it := RetryMapCtx(ctx, Range(0, 5, 1), fn, 3, func(int) time.Duration { return time.Hour })
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := it(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
+
+func TestRetryMapNilBackoff(t *testing.T) {
+	fn, _ := flaky(0, nil)
+	if _, err := RetryMap(Range(0, 3, 1), fn, 2, nil)(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+	// A single attempt never waits.
+	if got, err := ToSlice(RetryMap(Range(0, 3, 1), fn, 1, nil)); err != nil || len(got) != 3 {
+		t.Fatalf("got %v, %v, want 3 elements, nil", got, err)
+	}
+}
@@ -0,0 +1,135 @@
+package iter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// SampleByKey returns an iterator keeping the elements whose key hashes
+// with the seed below fraction, so about the fraction of all keys is kept
+// with all their elements. The decision for a key depends only on the key
+// and the seed, so it's the same across runs and shards using the same seed.
+//
+// Keys are hashed by their contents, equal keys get the same decision.
+// Keys containing pointers or channels aren't stable across runs.
+// Fraction outside of [0, 1] results in an iterator returning ErrInvalidArgument.
+func SampleByKey[T any, K comparable](source Iterator[T], key func(T) K, fraction float64, seed uint64) Iterator[T] {
+	if !(fraction >= 0 && fraction <= 1) {
+		return Err[T](fmt.Errorf("%w: fraction %v is out of range [0, 1]", ErrInvalidArgument, fraction))
+	}
+	if fraction == 1 {
+		return source
+	}
+	return func() (T, error) {
+		for {
+			value, err := source()
+			if err != nil {
+				return value, err
+			}
+			if float64(stableHash(seed, key(value)))/math.MaxUint64 < fraction {
+				return value, nil
+			}
+		}
+	}
+}
+
+// stableHash returns a hash of the key which is the same across runs,
+// equal keys have equal hashes.
+func stableHash[K comparable](seed uint64, key K) uint64 {
+	h := stableHasher(14695981039346656037)
+	h.add(seed)
+	switch k := any(key).(type) {
+	case string:
+		h.addString(k)
+	case int:
+		h.add(uint64(k))
+	case int64:
+		h.add(uint64(k))
+	case uint64:
+		h.add(k)
+	case float64:
+		h.addFloat(k)
+	default:
+		h.addValue(reflect.ValueOf(k))
+	}
+	// FNV alone mixes the last bytes poorly, finish with the splitmix64 finalizer.
+	x := uint64(h)
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// stableHasher is an FNV-1a hash of values written by their contents.
+type stableHasher uint64
+
+func (h *stableHasher) addByte(b byte) {
+	*h ^= stableHasher(b)
+	*h *= 1099511628211
+}
+
+func (h *stableHasher) add(x uint64) {
+	for i := 0; i < 64; i += 8 {
+		h.addByte(byte(x >> i))
+	}
+}
+
+func (h *stableHasher) addString(s string) {
+	// The length keeps adjacent strings of a struct apart.
+	h.add(uint64(len(s)))
+	for i := 0; i < len(s); i++ {
+		h.addByte(s[i])
+	}
+}
+
+func (h *stableHasher) addFloat(f float64) {
+	if f == 0 {
+		// -0 is equal to 0 but has other bits.
+		f = 0
+	}
+	h.add(math.Float64bits(f))
+}
+
+// addValue writes the value the way == compares it.
+func (h *stableHasher) addValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.add(1)
+		} else {
+			h.add(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.add(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.add(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.addFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		h.addFloat(real(c))
+		h.addFloat(imag(c))
+	case reflect.String:
+		h.addString(v.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		h.add(uint64(v.Pointer()))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			h.addValue(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			h.addValue(v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			h.add(0)
+			return
+		}
+		h.addString(v.Elem().Type().String())
+		h.addValue(v.Elem())
+	}
+}
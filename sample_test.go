@@ -0,0 +1,123 @@
+package iter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+type event struct {
+	user string
+	seq  int
+}
+
+// events returns n events of every one of the users.
+func events(users, n int) []event {
+	var values []event
+	for i := 0; i < n; i++ {
+		for u := 0; u < users; u++ {
+			values = append(values, event{user: fmt.Sprintf("user-%d", u), seq: i})
+		}
+	}
+	return values
+}
+
+func sampleUsers(t *testing.T, input []event, fraction float64, seed uint64) []event {
+	t.Helper()
+	got, err := ToSlice(SampleByKey(FromSlice(input), func(e event) string { return e.user }, fraction, seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestSampleByKeyDeterministic(t *testing.T) {
+	input := events(1000, 3)
+	first := sampleUsers(t, input, 0.1, 42)
+	if second := sampleUsers(t, input, 0.1, 42); !slices.Equal(first, second) {
+		t.Fatal("two passes with the same seed kept different elements")
+	}
+	if other := sampleUsers(t, input, 0.1, 43); slices.Equal(first, other) {
+		t.Fatal("different seeds kept the same elements")
+	}
+}
+
+func TestSampleByKeyAllOrNothing(t *testing.T) {
+	got := sampleUsers(t, events(1000, 5), 0.3, 7)
+	counts := make(map[string]int)
+	for _, e := range got {
+		counts[e.user]++
+	}
+	for user, n := range counts {
+		if n != 5 {
+			t.Fatalf("kept %d of 5 events of %s", n, user)
+		}
+	}
+}
+
+func TestSampleByKeyRate(t *testing.T) {
+	const users = 100000
+	for _, fraction := range []float64{0.01, 0.25, 0.5} {
+		kept := len(sampleUsers(t, events(users, 1), fraction, 1))
+		// A generous bound of 5 standard deviations.
+		want := fraction * users
+		if d := math.Abs(float64(kept) - want); d > 5*math.Sqrt(want*(1-fraction)) {
+			t.Errorf("fraction %v: kept %d of %d keys, want about %v", fraction, kept, users, want)
+		}
+	}
+}
+
+func TestSampleByKeyBounds(t *testing.T) {
+	input := events(100, 2)
+	if got := sampleUsers(t, input, 1, 1); !slices.Equal(got, input) {
+		t.Fatalf("fraction 1 kept %d of %d elements", len(got), len(input))
+	}
+	if got := sampleUsers(t, input, 0, 1); len(got) != 0 {
+		t.Fatalf("fraction 0 kept %d elements", len(got))
+	}
+	for _, fraction := range []float64{-0.1, 1.1, math.NaN()} {
+		it := SampleByKey(Range(0, 3, 1), func(v int) int { return v }, fraction, 1)
+		if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("fraction %v: got %v, want ErrInvalidArgument", fraction, err)
+		}
+	}
+}
+
+func TestSampleByKeyEqualFloatKeys(t *testing.T) {
+	type reading struct {
+		sensor string
+		value  float64
+	}
+	negZero := math.Copysign(0, -1)
+	var input []reading
+	for i := 0; i < 200; i++ {
+		input = append(input, reading{fmt.Sprintf("s%d", i), 0}, reading{fmt.Sprintf("s%d", i), negZero})
+	}
+	for seed := uint64(0); seed < 20; seed++ {
+		zeros, err := ToSlice(SampleByKey(FromSlice(input), func(r reading) float64 { return r.value }, 0.5, seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(zeros) != 0 && len(zeros) != len(input) {
+			t.Fatalf("seed %d: kept %d of %d elements with equal float keys", seed, len(zeros), len(input))
+		}
+	}
+	got, err := ToSlice(SampleByKey(FromSlice(input), func(r reading) reading { return r }, 0.5, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[reading]int)
+	for _, r := range got {
+		counts[r]++
+	}
+	for r, n := range counts {
+		if n != 2 {
+			t.Fatalf("kept %d of 2 elements equal to %v", n, r)
+		}
+	}
+	if len(got) == 0 || len(got) == len(input) {
+		t.Fatalf("kept %d of %d elements with fraction 0.5", len(got), len(input))
+	}
+}
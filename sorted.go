@@ -0,0 +1,207 @@
+package iter
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnsorted is returned by iterators expecting a sorted input
+// when the input is not sorted.
+var ErrUnsorted = errors.New("unsorted input")
+
+// RunLengthEncode returns an iterator collapsing runs of equal adjacent elements
+// into pairs of the element and the length of the run.
+func RunLengthEncode[T comparable](source Iterator[T]) Iterator[Pair[T, int]] {
+	return runs(source, nil)
+}
+
+// CompactSorted works as RunLengthEncode for a sorted input,
+// the streaming equivalent of "sort | uniq -c".
+// It returns ErrUnsorted and stops if an element is less than the previous one.
+func CompactSorted[T cmp.Ordered](source Iterator[T]) Iterator[Pair[T, int]] {
+	return runs(source, func(prev, next T) error {
+		if next < prev {
+			return fmt.Errorf("%w: %v after %v", ErrUnsorted, next, prev)
+		}
+		return nil
+	})
+}
+
+// runs collapses runs of equal adjacent elements,
+// check is called for every pair of adjacent different elements.
+func runs[T comparable](source Iterator[T], check func(prev, next T) error) Iterator[Pair[T, int]] {
+	var (
+		current T
+		count   int
+		done    bool
+	)
+	return func() (Pair[T, int], error) {
+		for !done {
+			value, err := source()
+			if errors.Is(err, ErrStopIt) {
+				done = true
+				break
+			}
+			if err != nil {
+				return Pair[T, int]{}, err
+			}
+			if count > 0 && value == current {
+				count++
+				continue
+			}
+			if count == 0 {
+				current, count = value, 1
+				continue
+			}
+			if check != nil {
+				if err := check(current, value); err != nil {
+					done, count = true, 0
+					return Pair[T, int]{}, err
+				}
+			}
+			run := Pair[T, int]{current, count}
+			current, count = value, 1
+			return run, nil
+		}
+		if count == 0 {
+			return Pair[T, int]{}, ErrStopIt
+		}
+		run := Pair[T, int]{current, count}
+		count = 0
+		return run, nil
+	}
+}
+
+// MergeSortedUnique returns an iterator merging sorted iterators
+// into one sorted iterator without duplicates.
+//
+// Only the current element of every iterator is kept in memory.
+// The first error of an iterator is returned and stops the merged iterator.
+func MergeSortedUnique[T cmp.Ordered](iters ...Iterator[T]) Iterator[T] {
+	return MergeSortedUniqueFunc(cmp.Compare[T], iters...)
+}
+
+// MergeSortedUniqueFunc works as MergeSortedUnique for iterators
+// sorted according to the comparison function fn,
+// elements are equal if fn returns 0 for them.
+func MergeSortedUniqueFunc[T any](fn func(a, b T) int, iters ...Iterator[T]) Iterator[T] {
+	merged := mergeSorted(fn, iters)
+	var (
+		last    T
+		emitted bool
+	)
+	return func() (T, error) {
+		for {
+			value, err := merged()
+			if err != nil {
+				return value, err
+			}
+			if emitted && fn(last, value) == 0 {
+				continue
+			}
+			last, emitted = value, true
+			return value, nil
+		}
+	}
+}
+
+// mergeHeap is a heap of the current elements of merged iterators.
+type mergeHeap[T any] struct {
+	heads   []T
+	sources []int
+	fn      func(a, b T) int
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.heads) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	if c := h.fn(h.heads[i], h.heads[j]); c != 0 {
+		return c < 0
+	}
+	return h.sources[i] < h.sources[j]
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.heads[i], h.heads[j] = h.heads[j], h.heads[i]
+	h.sources[i], h.sources[j] = h.sources[j], h.sources[i]
+}
+
+func (h *mergeHeap[T]) Push(x any) {
+	head := x.(Pair[T, int])
+	h.heads = append(h.heads, head.Left)
+	h.sources = append(h.sources, head.Right)
+}
+
+func (h *mergeHeap[T]) Pop() any {
+	last := len(h.heads) - 1
+	head := Pair[T, int]{h.heads[last], h.sources[last]}
+	h.heads, h.sources = h.heads[:last], h.sources[:last]
+	return head
+}
+
+// mergeSorted merges sorted iterators with a k-way merge,
+// equal elements are yielded in the order of their iterators.
+func mergeSorted[T any](fn func(a, b T) int, iters []Iterator[T]) Iterator[T] {
+	h := &mergeHeap[T]{fn: fn}
+	started, done := false, false
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started = true
+			for i, it := range iters {
+				value, err := it()
+				if errors.Is(err, ErrStopIt) {
+					continue
+				}
+				if err != nil {
+					done = true
+					return empty, err
+				}
+				h.heads = append(h.heads, value)
+				h.sources = append(h.sources, i)
+			}
+			heap.Init(h)
+		}
+		if h.Len() == 0 {
+			done = true
+			return empty, ErrStopIt
+		}
+		value, source := h.heads[0], h.sources[0]
+		next, err := iters[source]()
+		switch {
+		case errors.Is(err, ErrStopIt):
+			heap.Pop(h)
+		case err != nil:
+			done = true
+			return empty, err
+		default:
+			h.heads[0] = next
+			heap.Fix(h, 0)
+		}
+		return value, nil
+	}
+}
+
+// Sorted returns an iterator over elements of the source sorted by less.
+// The source is drained into memory on the first call, so it must be finite.
+// An error during draining is returned before any element is yielded.
+func Sorted[T any](source Iterator[T], less func(a, b T) bool) Iterator[T] {
+	return buffered(source, func(values []T) []T {
+		sort.Slice(values, func(i, j int) bool { return less(values[i], values[j]) })
+		return values
+	})
+}
+
+// SortedStable works as Sorted keeping the order of equal elements.
+func SortedStable[T any](source Iterator[T], less func(a, b T) bool) Iterator[T] {
+	return buffered(source, func(values []T) []T {
+		sort.SliceStable(values, func(i, j int) bool { return less(values[i], values[j]) })
+		return values
+	})
+}
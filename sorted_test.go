@@ -0,0 +1,158 @@
+package iter
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestCompactSorted(t *testing.T) {
+	got, err := ToSlice(CompactSorted(FromSlice([]int{1, 1, 2, 3, 3, 3})))
+	want := []Pair[int, int]{{1, 2}, {2, 1}, {3, 3}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestCompactSortedUnsorted(t *testing.T) {
+	it := CompactSorted(FromSlice([]int{1, 1, 3, 2, 2, 4}))
+	if p, err := it(); err != nil || p != (Pair[int, int]{1, 2}) {
+		t.Fatalf("got %v, %v, want {1 2}, nil", p, err)
+	}
+	if _, err := it(); !errors.Is(err, ErrUnsorted) {
+		t.Fatalf("got %v, want ErrUnsorted", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestRunLengthEncodeUnsorted(t *testing.T) {
+	// RunLengthEncode accepts any order, equal elements in different runs stay apart.
+	got, err := ToSlice(RunLengthEncode(FromSlice([]int{3, 3, 1, 3})))
+	want := []Pair[int, int]{{3, 2}, {1, 1}, {3, 1}}
+	if err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestMergeSortedUnique(t *testing.T) {
+	// Shards share most of their values and repeat them within a shard.
+	rng := rand.New(rand.NewSource(1))
+	want := make(map[int]bool)
+	var shards []Iterator[int]
+	for s := 0; s < 5; s++ {
+		var shard []int
+		for i := 0; i < 1000; i++ {
+			shard = append(shard, rng.Intn(300))
+		}
+		slices.Sort(shard)
+		for _, v := range shard {
+			want[v] = true
+		}
+		shards = append(shards, FromSlice(shard))
+	}
+	got, err := ToSlice(MergeSortedUnique(shards...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("element %d: %d after %d", i, got[i], got[i-1])
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d distinct values, want %d", len(got), len(want))
+	}
+	for _, v := range got {
+		if !want[v] {
+			t.Fatalf("got %d, which isn't in the shards", v)
+		}
+	}
+}
+
+func TestMergeSortedUniqueFunc(t *testing.T) {
+	a := FromSlice([]Pair[int, string]{{1, "a1"}, {2, "a2"}, {4, "a4"}})
+	b := FromSlice([]Pair[int, string]{{1, "b1"}, {3, "b3"}, {4, "b4"}})
+	got, err := ToSlice(MergeSortedUniqueFunc(func(x, y Pair[int, string]) int { return x.Left - y.Left }, a, b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []int
+	for _, p := range got {
+		keys = append(keys, p.Left)
+	}
+	if want := []int{1, 2, 3, 4}; !slices.Equal(keys, want) {
+		t.Fatalf("got keys %v, want %v", keys, want)
+	}
+}
+
+func TestMergeSortedUniqueError(t *testing.T) {
+	failure := errors.New("failure")
+	if _, err := ToSlice(MergeSortedUnique(Range(0, 5, 1), Err[int](failure))); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestSorted(t *testing.T) {
+	got, err := ToSlice(Sorted(FromSlice([]int{3, 1, 2, 1}), func(a, b int) bool { return a < b }))
+	if want := []int{1, 1, 2, 3}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	input := []Pair[int, string]{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}}
+	stable, err := ToSlice(SortedStable(FromSlice(input), func(a, b Pair[int, string]) bool { return a.Left < b.Left }))
+	want := []Pair[int, string]{{1, "b"}, {1, "d"}, {2, "a"}, {2, "c"}}
+	if err != nil || !slices.Equal(stable, want) {
+		t.Fatalf("got %v, %v, want %v, nil", stable, err, want)
+	}
+}
+
+func benchmarkValues() []int {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = rng.Int()
+	}
+	return values
+}
+
+func BenchmarkSorted(b *testing.B) {
+	values := benchmarkValues()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ToSlice(Sorted(FromSlice(slices.Clone(values)), func(a, b int) bool { return a < b })); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSortedStable(b *testing.B) {
+	values := benchmarkValues()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ToSlice(SortedStable(FromSlice(slices.Clone(values)), func(a, b int) bool { return a < b })); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSortSlice is the manual equivalent of Sorted for comparison.
+func BenchmarkSortSlice(b *testing.B) {
+	values := benchmarkValues()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		all, err := ToSlice(FromSlice(slices.Clone(values)))
+		if err != nil {
+			b.Fatal(err)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		if _, err := ToSlice(FromSlice(all)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
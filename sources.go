@@ -0,0 +1,105 @@
+package iter
+
+import (
+	"cmp"
+	"errors"
+	"sort"
+	"unicode/utf8"
+)
+
+// FromSlice returns an iterator over elements of the slice.
+func FromSlice[T any](values []T) Iterator[T] {
+	i := 0
+	return func() (T, error) {
+		if i >= len(values) {
+			var empty T
+			return empty, ErrStopIt
+		}
+		i++
+		return values[i-1], nil
+	}
+}
+
+// Empty returns an iterator without elements, it always returns ErrStopIt.
+func Empty[T any]() Iterator[T] {
+	return func() (T, error) {
+		var empty T
+		return empty, ErrStopIt
+	}
+}
+
+// Err returns an iterator that always fails with err.
+// Nil err or ErrStopIt makes it an Empty iterator.
+func Err[T any](err error) Iterator[T] {
+	if err == nil || errors.Is(err, ErrStopIt) {
+		return Empty[T]()
+	}
+	return func() (T, error) {
+		var empty T
+		return empty, err
+	}
+}
+
+// ErrorOf is another name for Err, for pipelines that read better
+// with it next to Empty.
+func ErrorOf[T any](err error) Iterator[T] {
+	return Err[T](err)
+}
+
+// entries returns key/value pairs of the map.
+func entries[K comparable, V any](m map[K]V) []Pair[K, V] {
+	pairs := make([]Pair[K, V], 0, len(m))
+	for key, value := range m {
+		pairs = append(pairs, Pair[K, V]{Left: key, Right: value})
+	}
+	return pairs
+}
+
+// FromMap returns an iterator over key/value pairs of the map in unspecified order.
+// Entries are captured when the iterator is created,
+// so later changes of the map don't affect it.
+func FromMap[K comparable, V any](m map[K]V) Iterator[Pair[K, V]] {
+	return FromSlice(entries(m))
+}
+
+// FromMapSorted returns an iterator over key/value pairs of the map
+// in ascending order of keys. Entries are captured and sorted
+// when the iterator is created, so later changes of the map don't affect it.
+func FromMapSorted[K cmp.Ordered, V any](m map[K]V) Iterator[Pair[K, V]] {
+	return FromMapSortedFunc(m, cmp.Less[K])
+}
+
+// FromMapSortedFunc is FromMapSorted with keys ordered by less.
+func FromMapSortedFunc[K comparable, V any](m map[K]V, less func(a, b K) bool) Iterator[Pair[K, V]] {
+	pairs := entries(m)
+	sort.Slice(pairs, func(i, j int) bool {
+		return less(pairs[i].Left, pairs[j].Left)
+	})
+	return FromSlice(pairs)
+}
+
+// FromStringRunes returns an iterator over runes of the string decoded lazily.
+// Invalid UTF-8 is handled like in a range loop: utf8.RuneError is yielded
+// and the iterator advances by one byte.
+func FromStringRunes(s string) Iterator[rune] {
+	return func() (rune, error) {
+		if len(s) == 0 {
+			return 0, ErrStopIt
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		return r, nil
+	}
+}
+
+// FromStringBytes returns an iterator over bytes of the string.
+func FromStringBytes(s string) Iterator[byte] {
+	i := 0
+	return func() (byte, error) {
+		if i >= len(s) {
+			return 0, ErrStopIt
+		}
+		i++
+		return s[i-1], nil
+	}
+}
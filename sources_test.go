@@ -0,0 +1,167 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestErr(t *testing.T) {
+	failure := errors.New("failure")
+	it := Err[int](failure)
+	for i := 0; i < 2; i++ {
+		if _, err := it(); !errors.Is(err, failure) {
+			t.Fatalf("got %v, want %v", err, failure)
+		}
+	}
+	for _, err := range []error{nil, ErrStopIt} {
+		if _, got := Err[int](err)(); !errors.Is(got, ErrStopIt) {
+			t.Fatalf("Err(%v): got %v, want ErrStopIt", err, got)
+		}
+	}
+}
+
+func TestErrThroughPipes(t *testing.T) {
+	failure := errors.New("failure")
+	ctx := context.Background()
+	for name, it := range map[string]Iterator[int]{
+		"Map":         Map(Err[int](failure), func(v int) (int, error) { return v, nil }),
+		"Distinct":    Distinct(Err[int](failure)),
+		"Limit":       Limit(Err[int](failure), 10),
+		"ChainLazy":   ChainLazy(FromSlice([]Iterator[int]{Range(0, 3, 1), Err[int](failure), Range(0, 3, 1)})),
+		"Interleave":  Interleave(Range(0, 3, 1), Err[int](failure)),
+		"Merge":       Merge(ctx, Range(0, 3, 1), Err[int](failure)),
+		"Buffer":      Buffer(ctx, Err[int](failure), 4),
+		"Reverse":     Reverse(Err[int](failure)),
+		"SkipErrorsN": SkipErrorsN(Err[int](failure), 3),
+		"ParallelFilter": ParallelFilter(ctx, Err[int](failure), 2, func(int) (bool, error) {
+			return true, nil
+		}),
+	} {
+		if got, err := ToSlice(it); !errors.Is(err, failure) || got != nil {
+			t.Errorf("%s: got %v, %v, want nil, %v", name, got, err, failure)
+		}
+	}
+}
+
+func TestErrThroughFinalizers(t *testing.T) {
+	failure := errors.New("failure")
+	if _, err := CountDistinct(Err[int](failure)); !errors.Is(err, failure) {
+		t.Errorf("CountDistinct: got %v, want %v", err, failure)
+	}
+	if _, err := Compare(Range(0, 3, 1), Err[int](failure)); !errors.Is(err, failure) {
+		t.Errorf("Compare: got %v, want %v", err, failure)
+	}
+	if err := ForEachCtx(context.Background(), Err[int](failure), func(context.Context, int) error { return nil },
+		0, nil); !errors.Is(err, failure) {
+		t.Errorf("ForEachCtx: got %v, want %v", err, failure)
+	}
+	if _, err := NewStringReader(Err[string](failure)).Read(make([]byte, 4)); !errors.Is(err, failure) {
+		t.Errorf("NewStringReader: got %v, want %v", err, failure)
+	}
+	values, errs := ToChanErr(context.Background(), Err[int](failure))
+	for range values {
+		t.Error("ToChanErr: got a value, want none")
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, failure) {
+			t.Errorf("ToChanErr: got %v, want %v", err, failure)
+		}
+	case <-time.After(time.Second):
+		t.Error("ToChanErr: the error wasn't delivered")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	if got, err := ToSlice(Empty[string]()); err != nil || got != nil {
+		t.Fatalf("got %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestErrorOfComposition(t *testing.T) {
+	failure := errors.New("failure")
+	// A stage factory returns ErrorOf or Empty for invalid or missing configuration.
+	it := ChainLazy(FromSlice([]Iterator[int]{Empty[int](), Range(0, 2, 1), ErrorOf[int](failure), Range(5, 7, 1)}))
+	for _, want := range []int{0, 1} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if got, err := ToSlice(ChainLazy(FromSlice([]Iterator[int]{ErrorOf[int](nil), Empty[int]()}))); err != nil || got != nil {
+		t.Fatalf("got %v, %v for empty stages, want nil, nil", got, err)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	for _, m := range []map[string]int{nil, {}} {
+		if got, err := ToSlice(FromMap(m)); err != nil || len(got) != 0 {
+			t.Fatalf("got %v, %v for %#v, want no pairs", got, err, m)
+		}
+	}
+	m := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		m[fmt.Sprint("key-", i)] = i
+	}
+	it := FromMap(m)
+	m["added"] = -1
+	seen := make(map[string]bool)
+	pairs, err := ToSlice(it)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range pairs {
+		if seen[p.Left] {
+			t.Fatalf("key %s yielded twice", p.Left)
+		}
+		seen[p.Left] = true
+		if m[p.Left] != p.Right || p.Left == "added" {
+			t.Fatalf("got %v, which isn't an entry of the map when the iterator was created", p)
+		}
+	}
+	if len(seen) != 100 {
+		t.Fatalf("got %d keys, want 100", len(seen))
+	}
+}
+
+func TestFromMapSorted(t *testing.T) {
+	m := map[string]int{"b": 2, "c": 3, "a": 1, "": 0}
+	for run := 0; run < 5; run++ {
+		got, err := ToSlice(FromMapSorted(m))
+		want := []Pair[string, int]{{"", 0}, {"a", 1}, {"b", 2}, {"c", 3}}
+		if err != nil || !slices.Equal(got, want) {
+			t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+		}
+	}
+	got, err := ToSlice(FromMapSortedFunc(map[int]string{1: "a", 3: "c", 2: "b"}, func(a, b int) bool { return a > b }))
+	if want := []Pair[int, string]{{3, "c"}, {2, "b"}, {1, "a"}}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestFromStringRunes(t *testing.T) {
+	for _, s := range []string{"", "héllo, 世界", "a\xffb", "\xe4\xb8", "\xed\xa0\x80x", "é\x80"} {
+		var want []rune
+		for _, r := range s {
+			want = append(want, r)
+		}
+		got, err := ToSlice(FromStringRunes(s))
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%q: got %q, %v, want %q as a range loop", s, got, err, want)
+		}
+	}
+}
+
+func TestFromStringBytes(t *testing.T) {
+	s := "a\xffé"
+	got, err := ToSlice(FromStringBytes(s))
+	if err != nil || string(got) != s {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, s)
+	}
+}
@@ -0,0 +1,40 @@
+package iter
+
+import "database/sql"
+
+// FromRows returns an iterator of values made by scan from every row of rows.
+//
+// An error of scan, rows.Err or rows.Close is returned and stops the iterator.
+// Rows are closed once the iterator stops for any reason,
+// abandoning the iterator earlier leaves closing rows to the caller.
+func FromRows[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) Iterator[T] {
+	done := false
+	stop := func(err error) error {
+		done = true
+		closeErr := rows.Close()
+		if err == nil {
+			err = rows.Err()
+		}
+		if err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = ErrStopIt
+		}
+		return err
+	}
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		if !rows.Next() {
+			return empty, stop(nil)
+		}
+		value, err := scan(rows)
+		if err != nil {
+			return empty, stop(err)
+		}
+		return value, nil
+	}
+}
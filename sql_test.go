@@ -0,0 +1,125 @@
+package iter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+// fakeRows is a driver result of n integer rows failing with err after them.
+type fakeRows struct {
+	n, i   int
+	err    error
+	closed *bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v"} }
+
+func (r *fakeRows) Close() error {
+	*r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= r.n {
+		if r.err != nil {
+			return r.err
+		}
+		return io.EOF
+	}
+	dest[0] = int64(r.i)
+	r.i++
+	return nil
+}
+
+// fakeDB is a connector, connection and statement serving fakeRows.
+type fakeDB struct {
+	n      int
+	err    error
+	closed bool
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return db, nil }
+func (db *fakeDB) Driver() driver.Driver                         { return nil }
+func (db *fakeDB) Prepare(string) (driver.Stmt, error)           { return db, nil }
+func (db *fakeDB) Close() error                                  { return nil }
+func (db *fakeDB) Begin() (driver.Tx, error)                     { return nil, errors.New("no transactions") }
+func (db *fakeDB) NumInput() int                                 { return 0 }
+func (db *fakeDB) Exec([]driver.Value) (driver.Result, error)    { return nil, errors.New("no exec") }
+
+func (db *fakeDB) Query([]driver.Value) (driver.Rows, error) {
+	db.closed = false
+	return &fakeRows{n: db.n, err: db.err, closed: &db.closed}, nil
+}
+
+func queryFake(t *testing.T, db *fakeDB) *sql.Rows {
+	t.Helper()
+	conn := sql.OpenDB(db)
+	t.Cleanup(func() { conn.Close() })
+	rows, err := conn.Query("select v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func scanInt(rows *sql.Rows) (int, error) {
+	var v int
+	err := rows.Scan(&v)
+	return v, err
+}
+
+func TestFromRows(t *testing.T) {
+	db := &fakeDB{n: 5}
+	got, err := ToSlice(FromRows(queryFake(t, db), scanInt))
+	if want := []int{0, 1, 2, 3, 4}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if !db.closed {
+		t.Fatal("rows weren't closed after the iteration")
+	}
+}
+
+func TestFromRowsScanError(t *testing.T) {
+	failure := errors.New("failure")
+	db := &fakeDB{n: 5}
+	it := FromRows(queryFake(t, db), func(rows *sql.Rows) (int, error) {
+		v, err := scanInt(rows)
+		if v == 2 {
+			return 0, failure
+		}
+		return v, err
+	})
+	if _, err := ToSlice(it); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if !db.closed {
+		t.Fatal("rows weren't closed after the scan error")
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestFromRowsDriverError(t *testing.T) {
+	failure := errors.New("connection lost")
+	db := &fakeDB{n: 3, err: failure}
+	got := 0
+	it := FromRows(queryFake(t, db), scanInt)
+	var err error
+	for err == nil {
+		if _, err = it(); err == nil {
+			got++
+		}
+	}
+	if !errors.Is(err, failure) || got != 3 {
+		t.Fatalf("got %v after %d rows, want %v after 3", err, got, failure)
+	}
+	if !db.closed {
+		t.Fatal("rows weren't closed after the driver error")
+	}
+}
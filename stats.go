@@ -0,0 +1,78 @@
+package iter
+
+import (
+	"errors"
+	"sync"
+)
+
+// ByteStatsCollector accumulates statistics of elements passed through
+// MeasureBytes or MeasureStrings.
+// It's safe to read the statistics concurrently with the iteration.
+type ByteStatsCollector struct {
+	mu         sync.Mutex
+	count      int
+	totalBytes int64
+	maxElement int
+}
+
+// Stats returns the number of elements, their total size in bytes
+// and the size of the biggest element.
+func (c *ByteStatsCollector) Stats() (count int, totalBytes int64, maxElement int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count, c.totalBytes, c.maxElement
+}
+
+func (c *ByteStatsCollector) add(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	c.totalBytes += int64(size)
+	c.maxElement = max(c.maxElement, size)
+}
+
+// MeasureBytes returns an iterator passing through elements of the source
+// and a collector of their size statistics.
+func MeasureBytes(source Iterator[[]byte]) (Iterator[[]byte], *ByteStatsCollector) {
+	return measure(source)
+}
+
+// MeasureStrings works as MeasureBytes for strings.
+func MeasureStrings(source Iterator[string]) (Iterator[string], *ByteStatsCollector) {
+	return measure(source)
+}
+
+// ByteStats consumes the iterator and returns the number of elements,
+// their total size in bytes and the size of the biggest element.
+func ByteStats(it Iterator[[]byte]) (count int, totalBytes int64, maxElement int, err error) {
+	return drainStats(measure(it))
+}
+
+// StringStats works as ByteStats for strings.
+func StringStats(it Iterator[string]) (count int, totalBytes int64, maxElement int, err error) {
+	return drainStats(measure(it))
+}
+
+func measure[T []byte | string](source Iterator[T]) (Iterator[T], *ByteStatsCollector) {
+	collector := &ByteStatsCollector{}
+	return func() (T, error) {
+		value, err := source()
+		if err == nil {
+			collector.add(len(value))
+		}
+		return value, err
+	}, collector
+}
+
+func drainStats[T any](it Iterator[T], collector *ByteStatsCollector) (int, int64, int, error) {
+	for {
+		_, err := it()
+		if errors.Is(err, ErrStopIt) {
+			count, totalBytes, maxElement := collector.Stats()
+			return count, totalBytes, maxElement, nil
+		}
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+}
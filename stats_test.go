@@ -0,0 +1,50 @@
+package iter
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestByteStats(t *testing.T) {
+	count, total, biggest, err := ByteStats(FromSlice([][]byte{[]byte("ab"), nil, []byte("cdefg")}))
+	if err != nil || count != 3 || total != 7 || biggest != 5 {
+		t.Fatalf("got %d, %d, %d, %v, want 3, 7, 5, nil", count, total, biggest, err)
+	}
+	count, total, biggest, err = StringStats(FromSlice([]string{"héllo", "", "x"}))
+	if err != nil || count != 3 || total != 7 || biggest != 6 {
+		t.Fatalf("got %d, %d, %d, %v, want 3, 7, 6, nil", count, total, biggest, err)
+	}
+	count, total, biggest, err = StringStats(Empty[string]())
+	if err != nil || count != 0 || total != 0 || biggest != 0 {
+		t.Fatalf("got %d, %d, %d, %v, want zeros, nil", count, total, biggest, err)
+	}
+	failure := errors.New("failure")
+	if _, _, _, err := ByteStats(Err[[]byte](failure)); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestMeasureBytesPassthrough(t *testing.T) {
+	input := [][]byte{[]byte("one"), []byte("three"), []byte(""), []byte("four")}
+	it, stats := MeasureBytes(FromSlice(input))
+	got, err := ToSlice(it)
+	if err != nil || !slices.EqualFunc(got, input, bytes.Equal) {
+		t.Fatalf("got %q, %v, want %q, nil", got, err, input)
+	}
+	if count, total, biggest := stats.Stats(); count != 4 || total != 12 || biggest != 5 {
+		t.Fatalf("got %d, %d, %d, want 4, 12, 5", count, total, biggest)
+	}
+}
+
+func TestMeasureStringsPartial(t *testing.T) {
+	it, stats := MeasureStrings(FromSlice([]string{"a", "bb", "ccc"}))
+	got, err := ToSlice(Limit(it, 2))
+	if want := []string{"a", "bb"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if count, total, biggest := stats.Stats(); count != 2 || total != 3 || biggest != 2 {
+		t.Fatalf("got %d, %d, %d, want 2, 3, 2", count, total, biggest)
+	}
+}
@@ -0,0 +1,268 @@
+package iter
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sync"
+)
+
+// spillQueue is a queue keeping up to a limit of elements in memory
+// and the rest in a temporary file.
+type spillQueue[T any] struct {
+	codec    Codec[T]
+	dir      string
+	memLimit int
+
+	mem     []T
+	file    *os.File
+	reader  *os.File
+	writer  *bufio.Writer
+	encode  func(T) error
+	decode  Iterator[T]
+	pending int
+}
+
+func (q *spillQueue[T]) len() int {
+	return len(q.mem) + q.pending
+}
+
+func (q *spillQueue[T]) push(value T) error {
+	if q.pending == 0 && len(q.mem) < q.memLimit {
+		q.mem = append(q.mem, value)
+		return nil
+	}
+	if q.file == nil {
+		f, err := os.CreateTemp(q.dir, "iter-spill-*")
+		if err != nil {
+			return err
+		}
+		q.file, q.writer = f, bufio.NewWriter(f)
+		q.encode = q.codec.Encoder(q.writer)
+		reader, err := os.Open(f.Name())
+		if err != nil {
+			return err
+		}
+		q.reader = reader
+		q.decode = q.codec.Decoder(bufio.NewReader(reader))
+	}
+	if err := q.encode(value); err != nil {
+		return err
+	}
+	q.pending++
+	return nil
+}
+
+func (q *spillQueue[T]) pop() (T, error) {
+	if len(q.mem) > 0 {
+		var empty T
+		value := q.mem[0]
+		q.mem[0] = empty
+		q.mem = q.mem[1:]
+		return value, nil
+	}
+	if err := q.writer.Flush(); err != nil {
+		var empty T
+		return empty, err
+	}
+	value, err := q.decode()
+	if err != nil {
+		return value, err
+	}
+	q.pending--
+	if q.pending == 0 {
+		// The spilled backlog is consumed, the next one starts a new file.
+		q.remove()
+	}
+	return value, nil
+}
+
+// remove closes and removes the temporary file.
+func (q *spillQueue[T]) remove() {
+	if q.file == nil {
+		return
+	}
+	if q.reader != nil {
+		q.reader.Close()
+	}
+	q.file.Close()
+	os.Remove(q.file.Name())
+	q.file, q.reader, q.writer, q.encode, q.decode, q.pending = nil, nil, nil, nil, nil, 0
+}
+
+// TeeSpill returns n iterators each yielding all the elements of the source.
+//
+// Elements pulled from the source but not yet consumed by an iterator are buffered,
+// up to memLimit elements per iterator are kept in memory, the rest of the backlog
+// is spilled to a temporary file in dir encoded with the codec and read back
+// as the iterator catches up. The default directory for temporary files is used
+// if dir is empty. A temporary file is removed once its backlog is consumed
+// or the iterator stops, files of abandoned iterators stay in dir.
+// The iterators are safe for concurrent use.
+// An error of the source or of the spill file is returned once by the affected
+// iterators after the elements before it, then they stop.
+// Non-positive n results in no iterators.
+func TeeSpill[T any](source Iterator[T], n int, codec Codec[T], dir string, memLimit int) []Iterator[T] {
+	if n <= 0 {
+		return nil
+	}
+	var (
+		mu      sync.Mutex
+		queues  = make([]*spillQueue[T], n)
+		errs    = make([]error, n)
+		stopped = make([]bool, n)
+		done    bool
+	)
+	for i := range queues {
+		queues[i] = &spillQueue[T]{codec: codec, dir: dir, memLimit: max(memLimit, 0)}
+	}
+	branches := make([]Iterator[T], n)
+	for i := range branches {
+		branches[i] = func() (T, error) {
+			var empty T
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped[i] {
+				return empty, ErrStopIt
+			}
+			if queues[i].len() > 0 {
+				value, err := queues[i].pop()
+				if err != nil {
+					errs[i] = err
+				} else {
+					return value, nil
+				}
+			} else if !done && errs[i] == nil {
+				value, err := source()
+				if err == nil {
+					for j, q := range queues {
+						if j != i && !stopped[j] && errs[j] == nil {
+							if err := q.push(value); err != nil {
+								errs[j] = err
+							}
+						}
+					}
+					return value, nil
+				}
+				done = true
+				if !errors.Is(err, ErrStopIt) {
+					for j := range errs {
+						if errs[j] == nil {
+							errs[j] = err
+						}
+					}
+				}
+			}
+			stopped[i] = true
+			queues[i].remove()
+			queues[i].mem = nil
+			if errs[i] != nil {
+				return empty, errs[i]
+			}
+			return empty, ErrStopIt
+		}
+	}
+	return branches
+}
+
+// Tee returns n iterators each yielding all the elements of the source.
+//
+// Elements pulled from the source but not yet consumed by an iterator
+// are buffered in memory, so an iterator which is never consumed makes
+// the buffer grow with the whole source, use TeeSpill to bound the memory.
+// The iterators are safe for concurrent use.
+// An error of the source is returned once by every iterator
+// after the elements before it, then they stop.
+// Non-positive n results in no iterators.
+func Tee[T any](source Iterator[T], n int) []Iterator[T] {
+	if n <= 0 {
+		return nil
+	}
+	var (
+		mu       sync.Mutex
+		queues   = make([][]T, n)
+		reported = make([]bool, n)
+		done     bool
+		failure  error
+	)
+	branches := make([]Iterator[T], n)
+	for i := range branches {
+		branches[i] = func() (T, error) {
+			var empty T
+			mu.Lock()
+			defer mu.Unlock()
+			if len(queues[i]) > 0 {
+				value := queues[i][0]
+				queues[i][0] = empty
+				queues[i] = queues[i][1:]
+				return value, nil
+			}
+			if !done {
+				value, err := source()
+				if err == nil {
+					for j := range queues {
+						if j != i {
+							queues[j] = append(queues[j], value)
+						}
+					}
+					return value, nil
+				}
+				done = true
+				if !errors.Is(err, ErrStopIt) {
+					failure = err
+				}
+			}
+			if failure != nil && !reported[i] {
+				reported[i] = true
+				return empty, failure
+			}
+			return empty, ErrStopIt
+		}
+	}
+	return branches
+}
+
+// Memoize returns a function creating iterators which replay the source
+// from the beginning. Elements are pulled from the source when the first
+// of the iterators needs them and recorded, so iterators can be created
+// at any moment, before or after the source is consumed.
+// The iterators are safe for concurrent use.
+// An error of the source is recorded too and returned once by every iterator
+// after the elements before it, then they stop.
+func Memoize[T any](source Iterator[T]) (replay func() Iterator[T]) {
+	var (
+		mu       sync.Mutex
+		recorded []T
+		done     bool
+		failure  error
+	)
+	return func() Iterator[T] {
+		i := 0
+		reported := false
+		return func() (T, error) {
+			var empty T
+			mu.Lock()
+			defer mu.Unlock()
+			if i == len(recorded) && !done {
+				value, err := source()
+				if err == nil {
+					recorded = append(recorded, value)
+				} else {
+					done = true
+					if !errors.Is(err, ErrStopIt) {
+						failure = err
+					}
+				}
+			}
+			if i < len(recorded) {
+				i++
+				return recorded[i-1], nil
+			}
+			if failure != nil && !reported {
+				reported = true
+				return empty, failure
+			}
+			return empty, ErrStopIt
+		}
+	}
+}
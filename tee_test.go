@@ -0,0 +1,226 @@
+package iter
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestTeeSpillLargeLag(t *testing.T) {
+	const n = 50000
+	dir := t.TempDir()
+	branches := TeeSpill(Range(0, n, 1), 2, GobCodec[int]{}, dir, 10)
+	fast, err := ToSlice(branches[0])
+	if err != nil || len(fast) != n {
+		t.Fatalf("got %d elements, %v, want %d, nil", len(fast), err, n)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("got %d spill files for the lagging branch, want 1", len(entries))
+	}
+	slow, err := ToSlice(branches[1])
+	if err != nil || !slices.Equal(slow, fast) {
+		t.Fatalf("got %d elements, %v, want the same %d elements as the fast branch", len(slow), err, n)
+	}
+	for i, v := range slow {
+		if v != i {
+			t.Fatalf("element %d: got %d", i, v)
+		}
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestTeeSpillAlternatingLag(t *testing.T) {
+	// Branches take turns being ahead, so backlogs are spilled and drained repeatedly.
+	dir := t.TempDir()
+	branches := TeeSpill(Range(0, 1000, 1), 3, JSONCodec[int]{}, dir, 2)
+	got := make([][]int, 3)
+	for turn := 0; ; turn++ {
+		active := false
+		for i, branch := range branches {
+			steps := 1
+			if i == turn%3 {
+				steps = 50
+			}
+			for s := 0; s < steps; s++ {
+				v, err := branch()
+				if errors.Is(err, ErrStopIt) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				active = true
+				got[i] = append(got[i], v)
+			}
+		}
+		if !active {
+			break
+		}
+	}
+	for i := range got {
+		if len(got[i]) != 1000 {
+			t.Fatalf("branch %d: got %d elements, want 1000", i, len(got[i]))
+		}
+		for j, v := range got[i] {
+			if v != j {
+				t.Fatalf("branch %d, element %d: got %d", i, j, v)
+			}
+		}
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestTeeSpillConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	branches := TeeSpill(Range(0, 10000, 1), 4, GobCodec[int]{}, dir, 16)
+	results := make([][]int, len(branches))
+	var wg sync.WaitGroup
+	for i, branch := range branches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			results[i], err = ToSlice(branch)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, got := range results {
+		if len(got) != 10000 || !slices.IsSorted(got) {
+			t.Fatalf("branch %d: got %d elements, sorted %v, want all 10000 in order", i, len(got), slices.IsSorted(got))
+		}
+	}
+	assertEmptyDir(t, dir)
+}
+
+func TestTeeSpillError(t *testing.T) {
+	failure := errors.New("failure")
+	dir := t.TempDir()
+	branches := TeeSpill(Map(Range(0, 100, 1), func(v int) (int, error) {
+		if v == 50 {
+			return 0, failure
+		}
+		return v, nil
+	}), 2, GobCodec[int]{}, dir, 5)
+	for i, branch := range branches {
+		for j := 0; j < 50; j++ {
+			if v, err := branch(); err != nil || v != j {
+				t.Fatalf("branch %d: got %v, %v, want %d, nil", i, v, err, j)
+			}
+		}
+		if _, err := branch(); !errors.Is(err, failure) {
+			t.Fatalf("branch %d: got %v, want %v", i, err, failure)
+		}
+		if _, err := branch(); !errors.Is(err, ErrStopIt) {
+			t.Fatalf("branch %d: got %v after the error, want ErrStopIt", i, err)
+		}
+	}
+	assertEmptyDir(t, dir)
+}
+
+func benchmarkTee(b *testing.B, tee func(Iterator[int]) []Iterator[int]) {
+	for i := 0; i < b.N; i++ {
+		branches := tee(Range(0, 100000, 1))
+		for _, branch := range branches {
+			if _, err := ToSlice(branch); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkTee(b *testing.B) {
+	benchmarkTee(b, func(source Iterator[int]) []Iterator[int] {
+		return Tee(source, 2)
+	})
+}
+
+func BenchmarkTeeSpill(b *testing.B) {
+	dir := b.TempDir()
+	benchmarkTee(b, func(source Iterator[int]) []Iterator[int] {
+		return TeeSpill(source, 2, GobCodec[int]{}, dir, 1000)
+	})
+}
+
+func TestMemoizeReplayAfterStart(t *testing.T) {
+	pulls := 0
+	source := Range(0, 5, 1)
+	replay := Memoize(func() (int, error) {
+		pulls++
+		return source()
+	})
+	first := replay()
+	for i := 0; i < 3; i++ {
+		if v, err := first(); err != nil || v != i {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+		}
+	}
+	got, err := ToSlice(replay())
+	if err != nil || !slices.Equal(got, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("got %v, %v, want [0 1 2 3 4], nil", got, err)
+	}
+	rest, err := ToSlice(first)
+	if err != nil || !slices.Equal(rest, []int{3, 4}) {
+		t.Fatalf("got %v, %v, want [3 4], nil", rest, err)
+	}
+	if pulls != 6 {
+		t.Fatalf("source pulled %d times, want 6", pulls)
+	}
+}
+
+func TestMemoizeError(t *testing.T) {
+	failure := errors.New("failure")
+	replay := Memoize(ChainLazy(FromSlice([]Iterator[int]{Range(0, 2, 1), Err[int](failure)})))
+	for r := 0; r < 3; r++ {
+		it := replay()
+		for i := 0; i < 2; i++ {
+			if v, err := it(); err != nil || v != i {
+				t.Fatalf("replay %d: got %v, %v, want %d, nil", r, v, err, i)
+			}
+		}
+		if _, err := it(); !errors.Is(err, failure) {
+			t.Fatalf("replay %d: got %v, want %v", r, err, failure)
+		}
+		if _, err := it(); !errors.Is(err, ErrStopIt) {
+			t.Fatalf("replay %d: got %v after the error, want ErrStopIt", r, err)
+		}
+	}
+}
+
+func TestMemoizeConcurrentReplays(t *testing.T) {
+	const n = 1000
+	pulls := 0
+	source := Range(0, n, 1)
+	replay := Memoize(func() (int, error) {
+		pulls++
+		return source()
+	})
+	var wg sync.WaitGroup
+	results := make([][]int, 8)
+	errs := make([]error, len(results))
+	for r := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[r], errs[r] = ToSlice(replay())
+		}()
+	}
+	wg.Wait()
+	for r, got := range results {
+		if errs[r] != nil || len(got) != n {
+			t.Fatalf("replay %d: got %d elements, %v, want %d, nil", r, len(got), errs[r], n)
+		}
+		for i, v := range got {
+			if v != i {
+				t.Fatalf("replay %d: got %d at %d", r, v, i)
+			}
+		}
+	}
+	if pulls != n+1 {
+		t.Fatalf("source pulled %d times, want %d", pulls, n+1)
+	}
+}
@@ -0,0 +1,251 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxThrottlePending is the number of elements ThrottleByKey
+// holds back while their keys are throttled.
+const maxThrottlePending = 64
+
+// tokenBucket is a token bucket refilled at a constant rate up to a burst.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time, perSecond float64, burst int) {
+	b.tokens = min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+}
+
+// wait returns the time until the bucket has a token.
+func (b *tokenBucket) wait(perSecond float64) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+}
+
+// ThrottleByKey returns an iterator limiting the rate of elements of every key
+// to perSecond with bursts of up to burst elements, using a token bucket per key.
+//
+// Elements of a throttled key are held back while elements of other keys
+// keep flowing, so the order of elements of every key is preserved,
+// but the order across keys is not. Up to maxThrottlePending elements are held back,
+// the source is pulled in a separate goroutine while there is room for more of them,
+// so a slow source doesn't delay the release of the held back elements.
+// The source is never called concurrently, a pull in flight when the context
+// is cancelled finishes in the background and its element is dropped.
+// An error of the source is returned after the held back elements and stops the iterator.
+// Buckets of idle keys are evicted, so the memory doesn't grow with all the keys seen.
+// The context cancellation interrupts the waiting, its error is returned and stops the iterator.
+// Non-positive perSecond or burst result in an iterator returning ErrInvalidArgument.
+func ThrottleByKey[T any, K comparable](ctx context.Context, source Iterator[T], key func(T) K, perSecond float64, burst int) Iterator[T] {
+	return ThrottleByKeyClock(ctx, source, key, perSecond, burst, SystemClock)
+}
+
+// ThrottleByKeyClock works as ThrottleByKey using the clock.
+func ThrottleByKeyClock[T any, K comparable](ctx context.Context, source Iterator[T], key func(T) K,
+	perSecond float64, burst int, clock Clock) Iterator[T] {
+	if perSecond <= 0 || burst <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive rate %v or burst %d", ErrInvalidArgument, perSecond, burst))
+	}
+	var (
+		buckets  = make(map[K]*tokenBucket)
+		pending  []Pair[K, T]
+		done     bool
+		failure  error
+		sweepAt  = 2 * maxThrottlePending
+		canceled bool
+	)
+	bucket := func(k K, now time.Time) *tokenBucket {
+		b, ok := buckets[k]
+		if !ok {
+			b = &tokenBucket{tokens: float64(burst), last: now}
+			buckets[k] = b
+		}
+		b.refill(now, perSecond, burst)
+		return b
+	}
+	// evict removes buckets which are full, they are equivalent to new ones.
+	evict := func(now time.Time) {
+		if len(buckets) < sweepAt {
+			return
+		}
+		held := make(map[K]struct{}, len(pending))
+		for _, p := range pending {
+			held[p.Left] = struct{}{}
+		}
+		for k, b := range buckets {
+			if _, ok := held[k]; !ok {
+				if b.refill(now, perSecond, burst); b.tokens >= float64(burst) {
+					delete(buckets, k)
+				}
+			}
+		}
+		sweepAt = max(2*len(buckets), 2*maxThrottlePending)
+	}
+	// release returns the first held back element with a token for its key,
+	// or the time to wait for one.
+	release := func(now time.Time) (T, bool, time.Duration) {
+		var empty T
+		wait := time.Duration(-1)
+		if len(pending) == 0 {
+			return empty, false, wait
+		}
+		blocked := make(map[K]struct{})
+		for i, p := range pending {
+			if _, ok := blocked[p.Left]; ok {
+				continue
+			}
+			b := bucket(p.Left, now)
+			if b.tokens >= 1 {
+				b.tokens--
+				pending = append(pending[:i], pending[i+1:]...)
+				return p.Right, true, 0
+			}
+			blocked[p.Left] = struct{}{}
+			if d := b.wait(perSecond); wait < 0 || d < wait {
+				wait = d
+			}
+		}
+		return empty, false, wait
+	}
+	// pulled receives the result of the pull in flight, the source is called
+	// in a goroutine, so held back elements are released while it's blocked.
+	var pulled chan result[T]
+	return func() (T, error) {
+		var empty T
+		if canceled {
+			return empty, ErrStopIt
+		}
+		for {
+			now := clock.Now()
+			evict(now)
+			value, ok, wait := release(now)
+			if ok {
+				return value, nil
+			}
+			if done && len(pending) == 0 {
+				if failure != nil {
+					err := failure
+					failure = nil
+					return empty, err
+				}
+				return empty, ErrStopIt
+			}
+			if pulled == nil && !done && len(pending) < maxThrottlePending {
+				pulled = make(chan result[T], 1)
+				go func(ch chan<- result[T]) {
+					value, err := source()
+					ch <- result[T]{value, err}
+				}(pulled)
+			}
+			var timer <-chan time.Time
+			if wait >= 0 {
+				timer = clock.After(wait)
+			}
+			select {
+			case r := <-pulled:
+				pulled = nil
+				if r.err != nil {
+					done = true
+					if !errors.Is(r.err, ErrStopIt) {
+						failure = r.err
+					}
+					continue
+				}
+				pending = append(pending, Pair[K, T]{key(r.value), r.value})
+			case <-timer:
+			case <-ctx.Done():
+				canceled = true
+				return empty, ctx.Err()
+			}
+		}
+	}
+}
+
+// RateLimit returns an iterator limiting the rate of pulls from the source
+// to n per the period with bursts of up to n elements, using a token bucket.
+// The context cancellation interrupts the waiting, its error is returned and stops the iterator.
+// Non-positive n or per result in an iterator returning ErrInvalidArgument.
+func RateLimit[T any](ctx context.Context, source Iterator[T], n int, per time.Duration) Iterator[T] {
+	return RateLimitClock(ctx, source, n, per, SystemClock)
+}
+
+// RateLimitClock works as RateLimit using the clock.
+func RateLimitClock[T any](ctx context.Context, source Iterator[T], n int, per time.Duration, clock Clock) Iterator[T] {
+	if n <= 0 || per <= 0 {
+		return Err[T](fmt.Errorf("%w: non-positive rate %d per %v", ErrInvalidArgument, n, per))
+	}
+	perSecond := float64(n) / per.Seconds()
+	bucket := tokenBucket{tokens: float64(n), last: clock.Now()}
+	canceled := false
+	return func() (T, error) {
+		var empty T
+		if canceled {
+			return empty, ErrStopIt
+		}
+		for {
+			bucket.refill(clock.Now(), perSecond, n)
+			wait := bucket.wait(perSecond)
+			if wait == 0 {
+				break
+			}
+			select {
+			case <-clock.After(wait):
+			case <-ctx.Done():
+				canceled = true
+				return empty, ctx.Err()
+			}
+		}
+		bucket.tokens--
+		return source()
+	}
+}
+
+// RateLimitSafe returns a thread-safe version of RateLimit,
+// concurrent callers share the same bucket.
+func RateLimitSafe[T any](ctx context.Context, source Iterator[T], n int, per time.Duration) Iterator[T] {
+	return safe(RateLimit(ctx, source, n, per))
+}
+
+// Delay returns an iterator pausing for d before yielding every element
+// of the source, the element is pulled before the pause.
+// If immediateFirst is true the first element is yielded without the pause.
+// Errors of the source are returned without the pause.
+// The context cancellation interrupts the pause, its error is returned and stops the iterator.
+func Delay[T any](ctx context.Context, source Iterator[T], d time.Duration, immediateFirst bool) Iterator[T] {
+	return DelayClock(ctx, source, d, immediateFirst, SystemClock)
+}
+
+// DelayClock works as Delay using the clock.
+func DelayClock[T any](ctx context.Context, source Iterator[T], d time.Duration, immediateFirst bool, clock Clock) Iterator[T] {
+	first := immediateFirst
+	canceled := false
+	return func() (T, error) {
+		var empty T
+		if canceled {
+			return empty, ErrStopIt
+		}
+		value, err := source()
+		if err != nil {
+			return empty, err
+		}
+		if first {
+			first = false
+			return value, nil
+		}
+		select {
+		case <-clock.After(d):
+			return value, nil
+		case <-ctx.Done():
+			canceled = true
+			return empty, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package iter
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type keyed struct {
+	key string
+	seq int
+}
+
+func TestThrottleByKeyPacing(t *testing.T) {
+	clock := newFakeClock()
+	var input []keyed
+	for i := 0; i < 20; i++ {
+		input = append(input, keyed{key: []string{"a", "b", "c"}[i%3], seq: i})
+	}
+	it := ThrottleByKeyClock(context.Background(), FromSlice(input),
+		func(k keyed) string { return k.key }, 10, 2, clock)
+	last := make(map[string][]time.Time)
+	var got []keyed
+	for {
+		v, err := it()
+		if errors.Is(err, ErrStopIt) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+		last[v.key] = append(last[v.key], clock.Now())
+	}
+	if len(got) != len(input) {
+		t.Fatalf("got %d elements, want %d", len(got), len(input))
+	}
+	for key, times := range last {
+		// The burst of 2 is released at once, then one element per 100ms.
+		for i := 2; i < len(times); i++ {
+			want := time.Duration(i-1) * 100 * time.Millisecond
+			if d := times[i].Sub(times[0]); d < want-time.Microsecond {
+				t.Errorf("key %s: element %d released after %v, want at least %v", key, i, d, want)
+			}
+		}
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		var want, seen []int
+		for _, v := range input {
+			if v.key == key {
+				want = append(want, v.seq)
+			}
+		}
+		for _, v := range got {
+			if v.key == key {
+				seen = append(seen, v.seq)
+			}
+		}
+		if !slices.Equal(seen, want) {
+			t.Errorf("key %s: got order %v, want %v", key, seen, want)
+		}
+	}
+}
+
+func TestThrottleByKeySlowSource(t *testing.T) {
+	clock := newFakeClock()
+	block := make(chan string)
+	defer close(block)
+	values := []string{"a", "a"}
+	source := func() (string, error) {
+		if len(values) > 0 {
+			v := values[0]
+			values = values[1:]
+			return v, nil
+		}
+		v, ok := <-block
+		if !ok {
+			return "", ErrStopIt
+		}
+		return v, nil
+	}
+	it := ThrottleByKeyClock(context.Background(), source, func(s string) string { return s }, 10, 1, clock)
+	start := clock.Now()
+	if _, err := it(); err != nil {
+		t.Fatal(err)
+	}
+	finished := make(chan error, 1)
+	go func() {
+		_, err := it()
+		finished <- err
+	}()
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("held back element wasn't released while the source is blocked")
+	}
+	if d := clock.Now().Sub(start); d != 100*time.Millisecond {
+		t.Fatalf("second element released after %v, want 100ms", d)
+	}
+}
+
+func TestThrottleByKeyCancelBlockedSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	block := make(chan int)
+	defer close(block)
+	it := ThrottleByKey(ctx, FromChanSimple(block), func(v int) int { return v }, 10, 1)
+	finished := make(chan error, 1)
+	go func() {
+		_, err := it()
+		finished <- err
+	}()
+	cancel()
+	select {
+	case err := <-finished:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancellation didn't unblock the iterator")
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
+
+func TestThrottleByKeySourceError(t *testing.T) {
+	failure := errors.New("failure")
+	calls := 0
+	source := func() (int, error) {
+		calls++
+		if calls > 3 {
+			return 0, failure
+		}
+		return 1, nil
+	}
+	it := ThrottleByKeyClock(context.Background(), source, func(v int) int { return v }, 10, 1, newFakeClock())
+	for i := 0; i < 3; i++ {
+		if _, err := it(); err != nil {
+			t.Fatalf("element %d: %v", i, err)
+		}
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestThrottleByKeyInvalid(t *testing.T) {
+	it := ThrottleByKey(context.Background(), Range(0, 3, 1), func(v int) int { return v }, 0, 1)
+	if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRateLimitPacing(t *testing.T) {
+	clock := newFakeClock()
+	start := clock.Now()
+	it := RateLimitClock(context.Background(), Range(0, 12, 1), 4, time.Second, clock)
+	for i := 0; i < 12; i++ {
+		if v, err := it(); err != nil || v != i {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+		}
+		// The burst of 4 is released at once, then one element per 250ms.
+		want := time.Duration(max(i-3, 0)) * 250 * time.Millisecond
+		if d := clock.Now().Sub(start); d < want-time.Microsecond || d > want+time.Microsecond {
+			t.Fatalf("element %d released after %v, want %v", i, d, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := RateLimit(ctx, Range(0, 3, 1), 1, time.Hour)
+	if _, err := it(); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := it(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	it := RateLimit(context.Background(), Range(0, 3, 1), 1, 0)
+	if _, err := it(); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("got %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestDelayPacing(t *testing.T) {
+	for _, immediateFirst := range []bool{false, true} {
+		clock := newFakeClock()
+		start := clock.Now()
+		it := DelayClock(context.Background(), Range(0, 5, 1), 100*time.Millisecond, immediateFirst, clock)
+		for i := 0; i < 5; i++ {
+			if v, err := it(); err != nil || v != i {
+				t.Fatalf("got %v, %v, want %d, nil", v, err, i)
+			}
+			pauses := i + 1
+			if immediateFirst {
+				pauses = i
+			}
+			if d, want := clock.Now().Sub(start), time.Duration(pauses)*100*time.Millisecond; d != want {
+				t.Fatalf("immediateFirst %v: element %d released after %v, want %v", immediateFirst, i, d, want)
+			}
+		}
+		end := clock.Now()
+		if _, err := it(); !errors.Is(err, ErrStopIt) || clock.Now() != end {
+			t.Fatalf("got %v after %v, want ErrStopIt without the pause", err, clock.Now().Sub(end))
+		}
+	}
+}
+
+func TestDelayCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	it := Delay(ctx, Range(0, 3, 1), time.Hour, true)
+	if _, err := it(); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := it(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after cancellation, want ErrStopIt", err)
+	}
+}
@@ -0,0 +1,100 @@
+package iter
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNextTimeout is returned by NextTimeout when the source
+// didn't produce an element in time.
+var ErrNextTimeout = errors.New("next element timeout")
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+// NextTimeout returns an iterator that fails with ErrNextTimeout
+// if a single pull from the source takes longer than d.
+//
+// The source is called in a separate goroutine. If a pull times out
+// the goroutine keeps waiting for the source, and the element it
+// eventually produces is returned by the following call,
+// so no element is lost and the source is never called concurrently.
+func NextTimeout[T any](source Iterator[T], d time.Duration) Iterator[T] {
+	var pending chan result[T]
+	return func() (T, error) {
+		if pending == nil {
+			pending = make(chan result[T], 1)
+			go func(ch chan<- result[T]) {
+				value, err := source()
+				ch <- result[T]{value, err}
+			}(pending)
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case r := <-pending:
+			pending = nil
+			return r.value, r.err
+		case <-timer.C:
+			var empty T
+			return empty, ErrNextTimeout
+		}
+	}
+}
+
+// ErrTimeout is returned by Timeout when the source
+// didn't produce an element in time.
+var ErrTimeout = errors.New("timeout")
+
+// Timeout returns an iterator that fails with ErrTimeout
+// if a single pull from the source takes longer than d.
+//
+// Unlike NextTimeout the element of a timed out pull is discarded
+// when it arrives, the following call pulls a new one. An error or ErrStopIt
+// of a timed out pull isn't discarded, it's returned by the following call.
+// The source is called in a separate goroutine and never concurrently,
+// so a hung pull makes the following calls time out too.
+// The iterator isn't called again after it returns ErrStopIt.
+func Timeout[T any](source Iterator[T], d time.Duration) Iterator[T] {
+	var pending chan result[T]
+	stale := false
+	done := false
+	pull := func() {
+		pending = make(chan result[T], 1)
+		go func(ch chan<- result[T]) {
+			value, err := source()
+			ch <- result[T]{value, err}
+		}(pending)
+	}
+	return func() (T, error) {
+		var empty T
+		if done {
+			return empty, ErrStopIt
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		for {
+			if pending == nil {
+				pull()
+			}
+			select {
+			case r := <-pending:
+				pending = nil
+				if stale && r.err == nil {
+					stale = false
+					continue
+				}
+				stale = false
+				if errors.Is(r.err, ErrStopIt) {
+					done = true
+				}
+				return r.value, r.err
+			case <-timer.C:
+				stale = true
+				return empty, ErrTimeout
+			}
+		}
+	}
+}
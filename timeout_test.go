@@ -0,0 +1,123 @@
+package iter
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimeoutUnclosedChannel(t *testing.T) {
+	ch := make(chan int)
+	it := Timeout(FromChanSimple(ch), 10*time.Millisecond)
+	finished := make(chan error, 1)
+	go func() {
+		_, err := it()
+		finished <- err
+	}()
+	select {
+	case err := <-finished:
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("got %v, want ErrTimeout", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("iteration didn't unblock after the deadline")
+	}
+}
+
+func TestTimeoutDiscardsLateValue(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+	it := Timeout(func() (int, error) {
+		calls++
+		if calls == 1 {
+			<-release
+			return 1, nil
+		}
+		return 2, nil
+	}, 10*time.Millisecond)
+	if _, err := it(); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	close(release)
+	value, err := it()
+	if err != nil || value != 2 {
+		t.Fatalf("got %v, %v, want 2, nil", value, err)
+	}
+}
+
+func TestTimeoutKeepsLateStop(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+	it := Timeout(func() (int, error) {
+		calls++
+		if calls == 1 {
+			<-release
+			return 0, ErrStopIt
+		}
+		return 42, nil
+	}, 10*time.Millisecond)
+	if _, err := it(); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if value, err := it(); !errors.Is(err, ErrStopIt) {
+			t.Fatalf("got %v, %v, want ErrStopIt", value, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("source called %d times after it stopped", calls-1)
+	}
+}
+
+func TestTimeoutKeepsLateError(t *testing.T) {
+	failure := errors.New("failure")
+	release := make(chan struct{})
+	it := Timeout(func() (int, error) {
+		<-release
+		return 0, failure
+	}, 10*time.Millisecond)
+	if _, err := it(); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	close(release)
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+}
+
+func TestNextTimeoutLateElement(t *testing.T) {
+	release := make(chan struct{})
+	values := []int{1, 2}
+	var calls atomic.Int64
+	it := NextTimeout(func() (int, error) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+		if len(values) == 0 {
+			return 0, ErrStopIt
+		}
+		v := values[0]
+		values = values[1:]
+		return v, nil
+	}, 10*time.Millisecond)
+	if _, err := it(); !errors.Is(err, ErrNextTimeout) {
+		t.Fatalf("got %v, want ErrNextTimeout", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrNextTimeout) {
+		t.Fatalf("got %v while the pull is still blocked, want ErrNextTimeout", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("source called %d times during the blocked pull, want 1", n)
+	}
+	close(release)
+	for _, want := range []int{1, 2} {
+		if v, err := it(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+}
@@ -0,0 +1,128 @@
+package iter
+
+import (
+	"sort"
+	"sync"
+)
+
+// UniqueBy returns an iterator that yields only the first element
+// for every key and drops the following ones.
+// Every seen key is kept in memory.
+func UniqueBy[T any, K comparable](source Iterator[T], key func(T) K) Iterator[T] {
+	seen := make(map[K]struct{})
+	return func() (T, error) {
+		for {
+			value, err := source()
+			if err != nil {
+				return value, err
+			}
+			k := key(value)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+			return value, nil
+		}
+	}
+}
+
+// DupStats collects statistics of duplicates dropped by UniqueByStats.
+// It's safe to read the statistics concurrently with the iteration.
+type DupStats[K comparable] struct {
+	mu      sync.Mutex
+	counts  map[K]int
+	dropped int
+}
+
+// Dropped returns the number of dropped duplicates.
+func (s *DupStats[K]) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// TopOffenders returns up to n keys with the biggest number of dropped duplicates
+// paired with that number, in descending order of the number.
+// The order of keys with an equal number of duplicates is unspecified.
+func (s *DupStats[K]) TopOffenders(n int) []Pair[K, int] {
+	s.mu.Lock()
+	var top []Pair[K, int]
+	for k, c := range s.counts {
+		if c > 1 {
+			top = append(top, Pair[K, int]{k, c - 1})
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(top, func(i, j int) bool { return top[i].Right > top[j].Right })
+	if n < len(top) {
+		top = top[:max(n, 0)]
+	}
+	return top
+}
+
+// UniqueByStats works as UniqueBy and additionally collects
+// statistics of the dropped duplicates.
+func UniqueByStats[T any, K comparable](source Iterator[T], key func(T) K) (Iterator[T], *DupStats[K]) {
+	stats := &DupStats[K]{counts: make(map[K]int)}
+	return func() (T, error) {
+		for {
+			value, err := source()
+			if err != nil {
+				return value, err
+			}
+			k := key(value)
+			stats.mu.Lock()
+			count := stats.counts[k] + 1
+			stats.counts[k] = count
+			if count > 1 {
+				stats.dropped++
+			}
+			stats.mu.Unlock()
+			if count == 1 {
+				return value, nil
+			}
+		}
+	}, stats
+}
+
+// Distinct returns an iterator yielding every value of the source
+// only the first time it's seen.
+//
+// Every seen value is kept in memory, so for an unbounded stream
+// the memory grows as long as new values keep coming.
+func Distinct[T comparable](source Iterator[T]) Iterator[T] {
+	return UniqueBy(source, func(value T) T { return value })
+}
+
+// DistinctSafe works as Distinct and is safe for concurrent use.
+func DistinctSafe[T comparable](source Iterator[T]) Iterator[T] {
+	return safe(Distinct(source))
+}
+
+// DedupConsecutive returns an iterator dropping elements equal
+// to the previous yielded element, only the previous element is kept in memory.
+func DedupConsecutive[T comparable](source Iterator[T]) Iterator[T] {
+	return DedupConsecutiveFunc(source, func(a, b T) bool { return a == b })
+}
+
+// DedupConsecutiveFunc works as DedupConsecutive comparing elements with eq.
+// Errors of the source are returned and don't reset the previous element.
+func DedupConsecutiveFunc[T any](source Iterator[T], eq func(a, b T) bool) Iterator[T] {
+	var (
+		last    T
+		yielded bool
+	)
+	return func() (T, error) {
+		for {
+			value, err := source()
+			if err != nil {
+				return value, err
+			}
+			if yielded && eq(last, value) {
+				continue
+			}
+			last, yielded = value, true
+			return value, nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package iter
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUniqueByStats(t *testing.T) {
+	it, stats := UniqueByStats(FromSlice(strings.Split("a b a c a b d", " ")), strings.ToUpper)
+	got, err := ToSlice(it)
+	if want := []string{"a", "b", "c", "d"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	if stats.Dropped() != 3 {
+		t.Fatalf("got %d dropped, want 3", stats.Dropped())
+	}
+	if got, want := stats.TopOffenders(5), []Pair[string, int]{{"A", 2}, {"B", 1}}; !slices.Equal(got, want) {
+		t.Fatalf("got top offenders %v, want %v", got, want)
+	}
+	if got, want := stats.TopOffenders(1), []Pair[string, int]{{"A", 2}}; !slices.Equal(got, want) {
+		t.Fatalf("got top offender %v, want %v", got, want)
+	}
+	if got := stats.TopOffenders(0); len(got) != 0 {
+		t.Fatalf("got %v for no offenders, want none", got)
+	}
+}
+
+func TestUniqueByStatsLimit(t *testing.T) {
+	it, stats := UniqueByStats(FromSlice(strings.Split("a b a c a b d", " ")), strings.ToUpper)
+	got, err := ToSlice(Limit(it, 3))
+	if want := []string{"a", "b", "c"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	// Only the elements pulled before the third one was yielded are counted.
+	if stats.Dropped() != 1 {
+		t.Fatalf("got %d dropped, want 1", stats.Dropped())
+	}
+	if got, want := stats.TopOffenders(5), []Pair[string, int]{{"A", 1}}; !slices.Equal(got, want) {
+		t.Fatalf("got top offenders %v, want %v", got, want)
+	}
+}
+
+func TestUniqueByStatsConcurrentRead(t *testing.T) {
+	it, stats := UniqueByStats(Map(Range(0, 10000, 1), func(v int) (int, error) { return v % 100, nil }),
+		func(v int) int { return v })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			stats.Dropped()
+			stats.TopOffenders(3)
+		}
+	}()
+	got, err := ToSlice(it)
+	<-done
+	if err != nil || len(got) != 100 || stats.Dropped() != 9900 {
+		t.Fatalf("got %d elements, %v, %d dropped, want 100, nil, 9900", len(got), err, stats.Dropped())
+	}
+}
+
+func TestDistinctShuffled(t *testing.T) {
+	var input []int
+	for i := 0; i < 100; i++ {
+		for j := 0; j <= i%4; j++ {
+			input = append(input, i)
+		}
+	}
+	rand.New(rand.NewSource(1)).Shuffle(len(input), func(i, j int) { input[i], input[j] = input[j], input[i] })
+	got, err := ToSlice(Distinct(FromSlice(input)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first occurrences in the input order.
+	var want []int
+	seen := make(map[int]bool)
+	for _, v := range input {
+		if !seen[v] {
+			seen[v] = true
+			want = append(want, v)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestDistinctCycleLimit(t *testing.T) {
+	got, err := ToSlice(Limit(Distinct(CycleIterator(FromSlice([]int{3, 1, 3, 2}))), 3))
+	if want := []int{3, 1, 2}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestDistinctSafe(t *testing.T) {
+	it := DistinctSafe(Map(Range(0, 10000, 1), func(v int) (int, error) { return v % 500, nil }))
+	var (
+		mu   sync.Mutex
+		seen = make(map[int]int)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, err := it()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				seen[v]++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 500 {
+		t.Fatalf("got %d distinct values, want 500", len(seen))
+	}
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("value %d yielded %d times", v, n)
+		}
+	}
+}
+
+func TestDedupConsecutive(t *testing.T) {
+	got, err := ToSlice(DedupConsecutive(FromSlice(strings.Split("a a b b b a c c", " "))))
+	if want := []string{"a", "b", "a", "c"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+	got, err = ToSlice(DedupConsecutiveFunc(FromSlice(strings.Split("a A b B a", " ")), strings.EqualFold))
+	if want := []string{"a", "b", "a"}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, nil", got, err, want)
+	}
+}
+
+func TestDedupConsecutiveError(t *testing.T) {
+	failure := errors.New("failure")
+	source := FromSlice([]error{nil, failure, nil, nil})
+	it := DedupConsecutive(Map(source, func(err error) (int, error) { return 1, err }))
+	if v, err := it(); err != nil || v != 1 {
+		t.Fatalf("got %v, %v, want 1, nil", v, err)
+	}
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	// The duplicates after the error are still dropped.
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v, want ErrStopIt", err)
+	}
+}
@@ -0,0 +1,79 @@
+package iter
+
+import (
+	"io/fs"
+	"path"
+)
+
+type walkConfig struct {
+	skipErrors bool
+}
+
+// WalkOption configures WalkDir.
+type WalkOption func(*walkConfig)
+
+// WithSkipErrors makes WalkDir skip directories it fails to read
+// instead of returning the errors.
+func WithSkipErrors() WalkOption {
+	return func(c *walkConfig) {
+		c.skipErrors = true
+	}
+}
+
+// WalkDir returns an iterator of paths and entries of the file tree at root
+// in the order of fs.WalkDir: lexical, a directory goes before its contents.
+// Directories are read lazily, no goroutines are involved,
+// so the iterator can be abandoned at any moment.
+//
+// A failure to read a directory is returned and the walk continues
+// with the next entry, use WithSkipErrors to skip such directories silently.
+// A failure to stat root is returned and stops the iterator.
+func WalkDir(fsys fs.FS, root string, opts ...WalkOption) Iterator[Pair[string, fs.DirEntry]] {
+	var config walkConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	var (
+		stack   []Pair[string, fs.DirEntry]
+		pending string
+		started bool
+		done    bool
+	)
+	return func() (Pair[string, fs.DirEntry], error) {
+		var empty Pair[string, fs.DirEntry]
+		if done {
+			return empty, ErrStopIt
+		}
+		if !started {
+			started = true
+			info, err := fs.Stat(fsys, root)
+			if err != nil {
+				done = true
+				return empty, err
+			}
+			stack = append(stack, Pair[string, fs.DirEntry]{Left: root, Right: fs.FileInfoToDirEntry(info)})
+		}
+		if pending != "" {
+			dir := pending
+			pending = ""
+			entries, err := fs.ReadDir(fsys, dir)
+			// Children are pushed in reverse to pop them in lexical order.
+			for i := len(entries) - 1; i >= 0; i-- {
+				stack = append(stack, Pair[string, fs.DirEntry]{Left: path.Join(dir, entries[i].Name()), Right: entries[i]})
+			}
+			if err != nil && !config.skipErrors {
+				return empty, err
+			}
+		}
+		if len(stack) == 0 {
+			done = true
+			return empty, ErrStopIt
+		}
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if entry.Right.IsDir() {
+			pending = entry.Left
+		}
+		return entry, nil
+	}
+}
@@ -0,0 +1,117 @@
+package iter
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// lockedFS fails to read the locked directories and counts reads of directories.
+type lockedFS struct {
+	fstest.MapFS
+	locked map[string]bool
+	reads  int
+}
+
+func (f *lockedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.reads++
+	if f.locked[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+func walkTree() fstest.MapFS {
+	return fstest.MapFS{
+		"a/1.txt":        {},
+		"a/b/2.txt":      {},
+		"a/b/c/3.txt":    {},
+		"a/locked/4.txt": {},
+		"d/5.txt":        {},
+		"e.txt":          {},
+	}
+}
+
+// walkPaths returns the paths yielded by WalkDir and the errors it returned.
+func walkPaths(fsys fs.FS, root string, opts ...WalkOption) ([]string, []error) {
+	var (
+		paths []string
+		errs  []error
+	)
+	it := WalkDir(fsys, root, opts...)
+	for {
+		entry, err := it()
+		if errors.Is(err, ErrStopIt) {
+			return paths, errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		paths = append(paths, entry.Left)
+	}
+}
+
+func TestWalkDirOrder(t *testing.T) {
+	fsys := walkTree()
+	var want []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		want = append(want, path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, errs := walkPaths(fsys, ".")
+	if len(errs) != 0 || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, no errors", got, errs, want)
+	}
+	got, errs = walkPaths(fsys, "a/b")
+	if want := []string{"a/b", "a/b/2.txt", "a/b/c", "a/b/c/3.txt"}; len(errs) != 0 || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v, want %v, no errors", got, errs, want)
+	}
+}
+
+func TestWalkDirReadError(t *testing.T) {
+	fsys := &lockedFS{MapFS: walkTree(), locked: map[string]bool{"a/locked": true}}
+	want := []string{".", "a", "a/1.txt", "a/b", "a/b/2.txt", "a/b/c", "a/b/c/3.txt", "a/locked", "d", "d/5.txt", "e.txt"}
+	got, errs := walkPaths(fsys, ".")
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrPermission) {
+		t.Fatalf("got errors %v, want one fs.ErrPermission", errs)
+	}
+	// The walk continues after the locked directory.
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	got, errs = walkPaths(fsys, ".", WithSkipErrors())
+	if len(errs) != 0 || !slices.Equal(got, want) {
+		t.Fatalf("got %v, %v with WithSkipErrors, want %v, no errors", got, errs, want)
+	}
+}
+
+func TestWalkDirLazy(t *testing.T) {
+	fsys := &lockedFS{MapFS: walkTree()}
+	it := WalkDir(fsys, ".")
+	for i, want := range []string{".", "a", "a/1.txt"} {
+		entry, err := it()
+		if err != nil || entry.Left != want {
+			t.Fatalf("got %v, %v, want %s, nil", entry.Left, err, want)
+		}
+		// A directory is read only when the element after it is pulled.
+		if fsys.reads != i {
+			t.Fatalf("%d directories read after %d elements, want %d", fsys.reads, i+1, i)
+		}
+	}
+}
+
+func TestWalkDirMissingRoot(t *testing.T) {
+	it := WalkDir(walkTree(), "missing")
+	if _, err := it(); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v, want fs.ErrNotExist", err)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
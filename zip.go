@@ -0,0 +1,146 @@
+package iter
+
+import (
+	"errors"
+	"sync"
+)
+
+// Zip3 returns an iterator of triples of elements of a, b and c
+// taken at the same position, it stops when any of them stops.
+// Errors of the iterators are returned and stop the iterator.
+func Zip3[A, B, C any](a Iterator[A], b Iterator[B], c Iterator[C]) Iterator[Triple[A, B, C]] {
+	done := false
+	return func() (Triple[A, B, C], error) {
+		var triple Triple[A, B, C]
+		if done {
+			return triple, ErrStopIt
+		}
+		var err error
+		if triple.First, err = a(); err == nil {
+			if triple.Second, err = b(); err == nil {
+				triple.Third, err = c()
+			}
+		}
+		if err != nil {
+			done = true
+			return Triple[A, B, C]{}, err
+		}
+		return triple, nil
+	}
+}
+
+// Zip3Safe works as Zip3 and is safe for concurrent use,
+// the iterators are pulled under one mutex so they stay aligned.
+func Zip3Safe[A, B, C any](a Iterator[A], b Iterator[B], c Iterator[C]) Iterator[Triple[A, B, C]] {
+	return safe(Zip3(a, b, c))
+}
+
+// PairsLongest returns an iterator of pairs of elements of left and right
+// taken at the same position until both of them stop, fillLeft and fillRight
+// substitute the elements of the side which stopped first.
+// A stopped side isn't called anymore.
+// Errors of the iterators are returned and stop the iterator.
+func PairsLongest[T, K any](left Iterator[T], right Iterator[K], fillLeft T, fillRight K) Iterator[Pair[T, K]] {
+	leftDone, rightDone, failed := false, false, false
+	return func() (Pair[T, K], error) {
+		pair := Pair[T, K]{fillLeft, fillRight}
+		if failed {
+			return Pair[T, K]{}, ErrStopIt
+		}
+		if !leftDone {
+			value, err := left()
+			switch {
+			case errors.Is(err, ErrStopIt):
+				leftDone = true
+			case err != nil:
+				failed = true
+				return Pair[T, K]{}, err
+			default:
+				pair.Left = value
+			}
+		}
+		if !rightDone {
+			value, err := right()
+			switch {
+			case errors.Is(err, ErrStopIt):
+				rightDone = true
+			case err != nil:
+				failed = true
+				return Pair[T, K]{}, err
+			default:
+				pair.Right = value
+			}
+		}
+		if leftDone && rightDone {
+			return Pair[T, K]{}, ErrStopIt
+		}
+		return pair, nil
+	}
+}
+
+// Unzip returns iterators of left and right elements of pairs of the source.
+//
+// The iterators may be consumed at different rates and from different goroutines,
+// elements pulled from the source for one side are buffered for the other one,
+// so the memory grows with the consumption skew, and consuming only one side
+// buffers all the elements of the other one.
+// An error of the source is returned once by both iterators, then they stop.
+func Unzip[T, K any](source Iterator[Pair[T, K]]) (Iterator[T], Iterator[K]) {
+	var (
+		mu      sync.Mutex
+		lefts   []T
+		rights  []K
+		done    bool
+		failure error
+		seen    [2]bool
+	)
+	// pull buffers the next pair of the source, it returns false when nothing is left.
+	pull := func() bool {
+		if done {
+			return false
+		}
+		pair, err := source()
+		if err != nil {
+			done = true
+			if !errors.Is(err, ErrStopIt) {
+				failure = err
+			}
+			return false
+		}
+		lefts = append(lefts, pair.Left)
+		rights = append(rights, pair.Right)
+		return true
+	}
+	stop := func(side int) error {
+		if failure != nil && !seen[side] {
+			seen[side] = true
+			return failure
+		}
+		return ErrStopIt
+	}
+	left := func() (T, error) {
+		var empty T
+		mu.Lock()
+		defer mu.Unlock()
+		if len(lefts) == 0 && !pull() {
+			return empty, stop(0)
+		}
+		value := lefts[0]
+		lefts[0] = empty
+		lefts = lefts[1:]
+		return value, nil
+	}
+	right := func() (K, error) {
+		var empty K
+		mu.Lock()
+		defer mu.Unlock()
+		if len(rights) == 0 && !pull() {
+			return empty, stop(1)
+		}
+		value := rights[0]
+		rights[0] = empty
+		rights = rights[1:]
+		return value, nil
+	}
+	return left, right
+}
@@ -0,0 +1,115 @@
+package iter
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestZip3(t *testing.T) {
+	got, err := ToSlice(Zip3(Range(0, 3, 1), FromSlice([]string{"a", "b", "c", "d"}), RepeatN(true, 5)))
+	if err != nil || len(got) != 3 {
+		t.Fatalf("got %v, %v, want 3 triples stopping with the shortest", got, err)
+	}
+	if got[2] != (Triple[int, string, bool]{2, "c", true}) {
+		t.Fatalf("got %v, want {2 c true}", got[2])
+	}
+	failure := errors.New("failure")
+	it := Zip3(Range(0, 3, 1), Err[int](failure), Range(0, 3, 1))
+	if _, err := it(); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if _, err := it(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("got %v after the error, want ErrStopIt", err)
+	}
+}
+
+func TestZip3SafeAligned(t *testing.T) {
+	const n = 5000
+	it := Zip3Safe(Range(0, n, 1), Map(Range(0, n, 1), func(v int) (string, error) {
+		return strconv.Itoa(v), nil
+	}), Map(Range(0, n, 1), func(v int) (int, error) { return v * 2, nil }))
+	var (
+		mu    sync.Mutex
+		total int
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				triple, err := it()
+				if err != nil {
+					return
+				}
+				if triple.Second != strconv.Itoa(triple.First) || triple.Third != triple.First*2 {
+					t.Errorf("misaligned triple %v", triple)
+					return
+				}
+				mu.Lock()
+				total++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if total != n {
+		t.Fatalf("got %d triples, want %d", total, n)
+	}
+}
+
+func TestUnzipOneSide(t *testing.T) {
+	pairs := make([]Pair[int, string], 100)
+	for i := range pairs {
+		pairs[i] = Pair[int, string]{Left: i, Right: strconv.Itoa(i)}
+	}
+	left, right := Unzip(FromSlice(pairs))
+	lefts, err := ToSlice(left)
+	if err != nil || len(lefts) != 100 || lefts[99] != 99 {
+		t.Fatalf("got %d left elements, %v, want 100, nil", len(lefts), err)
+	}
+	// The right side is consumed only after the source is exhausted.
+	rights, err := ToSlice(right)
+	if err != nil || len(rights) != 100 {
+		t.Fatalf("got %d right elements, %v, want 100, nil", len(rights), err)
+	}
+	for i, v := range rights {
+		if v != strconv.Itoa(i) {
+			t.Fatalf("got %q at %d, want %q", v, i, strconv.Itoa(i))
+		}
+	}
+}
+
+func TestUnzipError(t *testing.T) {
+	failure := errors.New("failure")
+	source := ChainLazy(FromSlice([]Iterator[Pair[int, string]]{
+		FromSlice([]Pair[int, string]{{Left: 1, Right: "a"}, {Left: 2, Right: "b"}}),
+		Err[Pair[int, string]](failure),
+	}))
+	left, right := Unzip(source)
+	for _, want := range []int{1, 2} {
+		if v, err := left(); err != nil || v != want {
+			t.Fatalf("got %v, %v, want %d, nil", v, err, want)
+		}
+	}
+	if _, err := left(); !errors.Is(err, failure) {
+		t.Fatalf("left: got %v, want %v", err, failure)
+	}
+	if _, err := left(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("left: got %v after the error, want ErrStopIt", err)
+	}
+	// The right side gets its buffered elements before the same error.
+	for _, want := range []string{"a", "b"} {
+		if v, err := right(); err != nil || v != want {
+			t.Fatalf("got %q, %v, want %q, nil", v, err, want)
+		}
+	}
+	if _, err := right(); !errors.Is(err, failure) {
+		t.Fatalf("right: got %v, want %v", err, failure)
+	}
+	if _, err := right(); !errors.Is(err, ErrStopIt) {
+		t.Fatalf("right: got %v after the error, want ErrStopIt", err)
+	}
+}